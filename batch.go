@@ -0,0 +1,381 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// 批量插入，按最大占位符数和最大字节数拆分为多条INSERT ... VALUES (...), (...)
+type Batch struct {
+	maxPlaceholders int  // 单条sql的最大占位符数，0-按方言
+	maxPacket       int  // 单条sql的最大字节数，估算值
+	tx              bool // 全部分批在一个事务内执行
+}
+
+// 批量插入结果
+type BatchResult struct {
+	affected int64   // 影响行数，全部分批之和
+	ids      []int64 // 每批的首个自增id，SQLite按最后的id和影响行数推算，不支持LastInsertId的驱动（如PostgreSQL）为0
+}
+
+func (x *BatchResult) GetAffected() int64 {
+	return x.affected
+}
+
+func (x *BatchResult) GetIds() []int64 {
+	return x.ids
+}
+
+// 分批数
+func (x *BatchResult) GetChunks() int {
+	return len(x.ids)
+}
+
+func InsertBatch(driver *Driver, table string, columns []string, rows [][]interface{}) (*BatchResult, error) {
+	return (*Batch)(nil).Insert(context.Background(), driver, table, columns, rows)
+}
+
+// rows为结构体或结构体指针的切片，按db标签取列，db:"id,auto"的自增列忽略
+func InsertBatchStructs(driver *Driver, table string, rows interface{}) (*BatchResult, error) {
+	return (*Batch)(nil).InsertStructs(context.Background(), driver, table, rows)
+}
+
+// 不在事务内时，失败返回已执行的分批结果
+func (x *Batch) Insert(ctx context.Context, driver *Driver, table string, columns []string, rows [][]interface{}) (*BatchResult, error) {
+	if driver == nil {
+		return nil, ErrNilDriver
+	}
+
+	chunks, err := x.chunk(driver.GetDialect(), table, columns, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite的LastInsertId为本批最后插入的id
+	_, sqlite := driver.GetDialect().(SqliteDialect)
+
+	r := &BatchResult{}
+	err = x.each(ctx, driver, chunks, func() { r = &BatchResult{} }, func(ctx context.Context, e execer, query string, args []interface{}) (int64, error) {
+		result, err := e.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+
+		return r.add(result, sqlite), nil
+	})
+
+	if err != nil && x.GetTx() {
+		return nil, err
+	}
+
+	return r, err
+}
+
+func (x *Batch) InsertStructs(ctx context.Context, driver *Driver, table string, rows interface{}) (*BatchResult, error) {
+	columns, values, err := structRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return x.Insert(ctx, driver, table, columns, values)
+}
+
+func (x *Batch) GetMaxPlaceholders() int {
+	if x == nil {
+		return 0
+	}
+
+	return x.maxPlaceholders
+}
+
+// 零值或nil时为BatchMaxPacket
+func (x *Batch) GetMaxPacket() int {
+	if x == nil || x.maxPacket == 0 {
+		return BatchMaxPacket
+	}
+
+	return x.maxPacket
+}
+
+func (x *Batch) GetTx() bool {
+	return x != nil && x.tx
+}
+
+type batchChunk struct {
+	query string
+	args  []interface{}
+	rows  int // 行数
+}
+
+// 按占位符数和估算的字节数（含INSERT INTO ... VALUES）拆分，单行超出时单独成批
+func (x *Batch) chunk(d Dialect, table string, columns []string, rows [][]interface{}) ([]batchChunk, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("columns can't be empty")
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("rows can't be empty")
+	}
+
+	maxPlaceholders := x.GetMaxPlaceholders()
+	if maxPlaceholders == 0 || maxPlaceholders > d.GetMaxPlaceholders() {
+		maxPlaceholders = d.GetMaxPlaceholders()
+	}
+
+	perChunk := maxPlaceholders / len(columns)
+	if perChunk == 0 {
+		return nil, fmt.Errorf("%d columns can't be greater than max placeholders %d", len(columns), maxPlaceholders)
+	}
+
+	// INSERT INTO table (columns) VALUES，每个值的占位符按最长的计，另加", "，每行另加"(", ")", ", "
+	header := len("INSERT INTO  () VALUES ") + len(quoteIdent(d, table)) + len(quoteIdents(d, columns))
+	placeholder := len(d.Placeholder(perChunk*len(columns))) + 2
+
+	var r []batchChunk
+	var q *InsertQuery
+	var size, n int
+
+	flush := func() error {
+		if q == nil {
+			return nil
+		}
+
+		query, args, err := q.toSql(d)
+		if err != nil {
+			return err
+		}
+
+		r = append(r, batchChunk{query: query, args: args, rows: n})
+		q, size, n = nil, 0, 0
+		return nil
+	}
+
+	for _, row := range rows {
+		rowSize := estimateSize(row) + placeholder*len(row) + 4
+		if q != nil && (n >= perChunk || size+rowSize > x.GetMaxPacket()) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+
+		if q == nil {
+			q = NewInsert(table, columns...)
+			size = header
+		}
+
+		q.Values(row...)
+		size += rowSize
+		n++
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// 可执行sql的*sql.DB或*sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// 依次执行分批，每批经拦截器和熔断器记为一次写
+// 在事务内时，任一批失败时回滚，幂等的写按Driver的重试策略重试整个事务，每次尝试前调用reset
+func (x *Batch) each(ctx context.Context, driver *Driver, chunks []batchChunk, reset func(), fn func(ctx context.Context, e execer, query string, args []interface{}) (int64, error)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !x.GetTx() {
+		for _, chunk := range chunks {
+			err := run(ctx, driver, MethodExec, chunk.query, chunk.args, func(db *sql.DB, c *Call) error {
+				n, err := fn(c.ctx, db, c.query, c.args)
+				c.rows = n
+				return err
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	attempt := func(driver *Driver) error {
+		reset()
+		return x.inTx(ctx, driver, chunks, fn)
+	}
+
+	retry := driver.GetRetry()
+	if retry == nil || !retryable(ctx, MethodExec) {
+		return attempt(driver)
+	}
+
+	return retry.Do(ctx, func(tried []*Driver) (*Driver, error) {
+		return driver, nil
+	}, attempt)
+}
+
+// 在一个事务内依次执行分批，事务内的分批不单独重试
+func (x *Batch) inTx(ctx context.Context, driver *Driver, chunks []batchChunk, fn func(ctx context.Context, e execer, query string, args []interface{}) (int64, error)) error {
+	db, release, err := driver.acquire()
+	if err != nil {
+		return wrapQueryError(driver, "BEGIN", err)
+	}
+
+	defer release()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapQueryError(driver, "BEGIN", err)
+	}
+
+	chunkCtx := context.WithValue(ctx, idempotentKey{}, false)
+	for _, chunk := range chunks {
+		err := run(chunkCtx, driver, MethodExec, chunk.query, chunk.args, func(_ *sql.DB, c *Call) error {
+			n, err := fn(c.ctx, tx, c.query, c.args)
+			c.rows = n
+			return err
+		})
+
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	return wrapQueryError(driver, "COMMIT", tx.Commit())
+}
+
+// 返回本批的影响行数，last为LastInsertId是否是最后插入的id
+func (x *BatchResult) add(result sql.Result, last bool) int64 {
+	n, err := result.RowsAffected()
+	if err == nil {
+		x.affected += n
+	}
+
+	id, _ := result.LastInsertId()
+	if last && id > 0 && n > 0 {
+		id -= n - 1
+	}
+
+	x.ids = append(x.ids, id)
+	return n
+}
+
+// 估算参数的字节数
+func estimateSize(args []interface{}) int {
+	var n int
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			n += len(v) + 2
+		case []byte:
+			n += 2*len(v) + 3
+		default:
+			n += 20
+		}
+	}
+
+	return n
+}
+
+// 结构体切片转为列和行，列取第一个元素，其余元素的列需与之一致
+func structRows(rows interface{}) ([]string, [][]interface{}, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("rows must be a slice of struct, got %T", rows)
+	}
+
+	var columns []string
+	values := make([][]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		e := indirect(v.Index(i))
+		if !e.IsValid() || e.Kind() != reflect.Struct {
+			return nil, nil, fmt.Errorf("row %d must be a struct", i)
+		}
+
+		var names []string
+		var row []interface{}
+		for _, f := range structFields(e) {
+			if f.auto {
+				continue
+			}
+
+			names = append(names, f.name)
+			row = append(row, f.value)
+		}
+
+		if i == 0 {
+			columns = names
+		} else if !slices.Equal(names, columns) {
+			return nil, nil, fmt.Errorf("row %d has columns %v, want %v", i, names, columns)
+		}
+
+		values = append(values, row)
+	}
+
+	return columns, values, nil
+}
+
+type BatchBuilder struct {
+	mu              sync.Mutex // ensures atomic writes; protects the following fields
+	maxPlaceholders int
+	maxPacket       int
+	tx              bool
+}
+
+func (x *BatchBuilder) Build() (*Batch, error) {
+	if x.maxPlaceholders < 0 {
+		return nil, errors.New("max placeholders can't be less than 0")
+	}
+
+	if x.maxPacket < 0 {
+		return nil, errors.New("max packet can't be less than 0")
+	}
+
+	maxPacket := x.maxPacket
+	if maxPacket == 0 {
+		maxPacket = BatchMaxPacket
+	}
+
+	return &Batch{
+		maxPlaceholders: x.maxPlaceholders,
+		maxPacket:       maxPacket,
+		tx:              x.tx,
+	}, nil
+}
+
+// 单条sql的最大占位符数，不超过方言的上限，缺省：0-按方言
+func (x *BatchBuilder) SetMaxPlaceholders(n int) *BatchBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.maxPlaceholders = n
+	return x
+}
+
+// 单条sql的最大字节数，按参数估算，缺省：BatchMaxPacket
+func (x *BatchBuilder) SetMaxPacket(n int) *BatchBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.maxPacket = n
+	return x
+}
+
+// 全部分批在一个事务内执行，缺省：false
+func (x *BatchBuilder) SetTx(b bool) *BatchBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.tx = b
+	return x
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestInsertBatch(t *testing.T) {
+	setFakeResult("INSERT INTO `user` (`name`, `age`) VALUES (?, ?), (?, ?)", &fakeResult{affected: 2, insertId: 1})
+	setFakeResult("INSERT INTO `user` (`name`, `age`) VALUES (?, ?)", &fakeResult{affected: 1, insertId: 3})
+
+	d := newFakeDriver()
+
+	b := &BatchBuilder{}
+	batch, err := b.SetMaxPlaceholders(4).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := [][]interface{}{{"foo", 1}, {"bar", 2}, {"baz", 3}}
+	got, err := batch.Insert(context.Background(), d, "user", []string{"name", "age"}, rows)
+	if err != nil || got.GetAffected() != 3 || got.GetChunks() != 2 || got.GetIds()[1] != 3 {
+		t.Errorf("got %v, %v; want affected 3, ids [1 3]", got, err)
+	}
+
+	type user struct {
+		Id   int64  `db:"id,auto"`
+		Name string `db:"name"`
+		Age  int
+	}
+
+	batch2, err := b.SetTx(true).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got2, err := batch2.InsertStructs(context.Background(), d, "user", []*user{{Name: "foo", Age: 1}, {Name: "bar", Age: 2}, {Name: "baz", Age: 3}})
+	if err != nil || got2.GetAffected() != 3 || got2.GetChunks() != 2 {
+		t.Errorf("got %v, %v; want affected 3 in 2 chunks", got2, err)
+	}
+}
+
+func TestBatchMaxPacket(t *testing.T) {
+	setFakeResult("INSERT INTO `user` (`name`, `age`) VALUES (?, ?), (?, ?)", &fakeResult{affected: 2, insertId: 1})
+	setFakeResult("INSERT INTO `user` (`name`, `age`) VALUES (?, ?)", &fakeResult{affected: 1, insertId: 3})
+
+	if got := (&Batch{}).GetMaxPacket(); got != BatchMaxPacket {
+		t.Errorf("got %d; want %d", got, BatchMaxPacket)
+	}
+
+	// 每行估算42字节，INSERT INTO ... VALUES估算43字节，每批2行
+	batch, err := (&BatchBuilder{}).SetMaxPacket(150).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := [][]interface{}{{"0123456789", 1}, {"0123456789", 2}, {"0123456789", 3}}
+	got, err := batch.Insert(context.Background(), newFakeDriver(), "user", []string{"name", "age"}, rows)
+	if err != nil || got.GetAffected() != 3 || got.GetChunks() != 2 {
+		t.Errorf("got %v, %v; want affected 3 in 2 chunks", got, err)
+	}
+}
+
+func TestBatchTx(t *testing.T) {
+	setFakeResult("INSERT INTO `tx_user` (`name`, `age`) VALUES (?, ?), (?, ?)", &fakeResult{affected: 2})
+	setFakeResult("INSERT INTO `tx_user` (`name`, `age`) VALUES (?, ?)", &fakeResult{fails: -1, err: errors.New("fake: duplicate entry")})
+
+	d := newFakeDriver()
+
+	var calls []string
+	_ = d.AddInterceptor(InterceptorFunc(func(c *Call, next Handler) error {
+		calls = append(calls, fmt.Sprint(len(c.GetArgs())))
+		return next(c)
+	}))
+
+	batch, err := (&BatchBuilder{}).SetMaxPlaceholders(4).SetTx(true).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rollbacks := fakeRollbacks.Load()
+	rows := [][]interface{}{{"foo", 1}, {"bar", 2}, {"baz", 3}}
+	got, err := batch.Insert(context.Background(), d, "tx_user", []string{"name", "age"}, rows)
+	if err == nil || got != nil {
+		t.Errorf("got %v, %v; want nil, error", got, err)
+	}
+
+	if fakeRollbacks.Load() != rollbacks+1 {
+		t.Errorf("got %d rollbacks; want 1", fakeRollbacks.Load()-rollbacks)
+	}
+
+	if fmt.Sprint(calls) != "[4 2]" {
+		t.Errorf("got %v; want each chunk intercepted with its args [4 2]", calls)
+	}
+}
+
+func TestStructRowsNilEmbedded(t *testing.T) {
+	type Base struct {
+		ID int64 `db:"id"`
+	}
+
+	type row struct {
+		*Base
+		Name string `db:"name"`
+	}
+
+	columns, values, err := structRows([]row{{&Base{ID: 1}, "a"}, {nil, "b"}})
+	if err != nil || fmt.Sprint(columns) != "[name id]" || fmt.Sprint(values) != "[[a 1] [b 0]]" {
+		t.Errorf("got %v, %v, %v; want [name id], [[a 1] [b 0]]", columns, values, err)
+	}
+
+	_, _, err = structRows([]interface{}{row{Name: "a"}, struct{ Age int }{1}})
+	want := "row 1 has columns [age], want [name id]"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v; want %q", err, want)
+	}
+
+	got, args, err := BindNamed("mysql", "SELECT * FROM user WHERE id = :id AND name = :name", row{Name: "b"})
+	if err != nil || got != "SELECT * FROM user WHERE id = ? AND name = ?" || fmt.Sprint(args) != "[0 b]" {
+		t.Errorf("got %q, %v, %v; want zero id for nil embedded", got, args, err)
+	}
+}
+
+func TestInsertBatchFunc(t *testing.T) {
+	setFakeResult("INSERT INTO `member` (`name`, `age`) VALUES (?, ?), (?, ?)", &fakeResult{affected: 2, insertId: 5})
+	// SQLite按双引号引用标识符
+	setFakeResult(`INSERT INTO "member" ("name", "age") VALUES (?, ?), (?, ?)`, &fakeResult{affected: 2, insertId: 6})
+
+	got, err := InsertBatch(newFakeDriver(), "member", []string{"name", "age"}, [][]interface{}{{"foo", 1}, {"bar", 2}})
+	if err != nil || got.GetAffected() != 2 || fmt.Sprint(got.GetIds()) != "[5]" {
+		t.Errorf("got %v, %v; want affected 2, ids [5]", got, err)
+	}
+
+	type member struct {
+		Id   int64  `db:"id,auto"`
+		Name string `db:"name"`
+		Age  int    `db:"age"`
+	}
+
+	// SQLite的LastInsertId为最后的id，6 - 2 + 1
+	got2, err := InsertBatchStructs(newFakeNamedDriver("fakesqlite"), "member", []member{{Name: "foo", Age: 1}, {Name: "bar", Age: 2}})
+	if err != nil || got2.GetAffected() != 2 || fmt.Sprint(got2.GetIds()) != "[5]" {
+		t.Errorf("got %v, %v; want affected 2, ids [5]", got2, err)
+	}
+
+	if _, err := InsertBatchStructs(newFakeDriver(), "member", []int{1}); err == nil {
+		t.Errorf("got nil; want struct error")
+	}
+}
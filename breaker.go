@@ -0,0 +1,329 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // 关闭，正常调用
+	BreakerOpen                         // 打开，拒绝调用
+	BreakerHalfOpen                     // 半开，允许少量试探调用
+)
+
+func (x BreakerState) String() string {
+	switch x {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(x))
+	}
+}
+
+// 熔断器，每个Driver一个
+// 窗口内失败率达到阈值时打开，冷却后半开，试探调用全部成功时关闭，任一失败时重新打开
+// 失败：classifier判定的错误，或耗时超过latency
+type Breaker struct {
+	mu         sync.Mutex // ensures atomic transitions; protects the following fields
+	driver     *Driver
+	window     time.Duration
+	requests   int
+	errorRate  float64
+	latency    time.Duration
+	cooldown   time.Duration
+	trials     int
+	classifier func(err error) bool
+	onChange   func(driver *Driver, from BreakerState, to BreakerState)
+
+	state    BreakerState
+	start    time.Time // 窗口开始时间
+	total    int       // 窗口内调用数
+	failures int       // 窗口内失败数
+	openedAt time.Time // 打开时间
+	probes   int       // 半开时，已放行的试探调用数
+	passes   int       // 半开时，已成功的试探调用数
+	turns    uint64    // 状态变更次数，归还试探调用时校验是否仍是同一次半开
+}
+
+func (x *Breaker) GetState() BreakerState {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.current(time.Now())
+}
+
+// 是否可选用，即allow是否会放行，不占用试探调用
+// 打开且未冷却时，或半开且试探调用已放行完时不可选用
+func (x *Breaker) available() bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	switch x.current(time.Now()) {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		return x.probes < x.trials
+	default:
+		return true
+	}
+}
+
+// 是否放行本次调用，放行后须调用record记录结果，或未发起调用时调用release归还
+// 返回值turn用于release
+func (x *Breaker) allow() (uint64, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	switch x.current(time.Now()) {
+	case BreakerOpen:
+		return x.turns, false
+	case BreakerHalfOpen:
+		if x.probes >= x.trials {
+			return x.turns, false
+		}
+
+		x.probes++
+		return x.turns, true
+	default:
+		return x.turns, true
+	}
+}
+
+// 放行后未发起调用（如：打开sql.DB失败），归还半开时占用的试探调用
+func (x *Breaker) release(turn uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.turns == turn && x.state == BreakerHalfOpen && x.probes > 0 {
+		x.probes--
+	}
+}
+
+// 记录本次调用的结果
+func (x *Breaker) record(duration time.Duration, err error) {
+	failed := (err != nil && x.classifier(err)) || (x.latency > 0 && duration > x.latency)
+	now := time.Now()
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	switch x.current(now) {
+	case BreakerHalfOpen:
+		if failed {
+			x.transit(BreakerOpen, now)
+			return
+		}
+
+		x.passes++
+		if x.passes >= x.trials {
+			x.transit(BreakerClosed, now)
+		}
+	case BreakerClosed:
+		if now.Sub(x.start) >= x.window {
+			x.start, x.total, x.failures = now, 0, 0
+		}
+
+		x.total++
+		if failed {
+			x.failures++
+		}
+
+		if x.total >= x.requests && float64(x.failures)/float64(x.total) >= x.errorRate {
+			x.transit(BreakerOpen, now)
+		}
+	}
+}
+
+// 当前状态，打开且冷却结束时转为半开
+func (x *Breaker) current(now time.Time) BreakerState {
+	if x.state == BreakerOpen && now.Sub(x.openedAt) >= x.cooldown {
+		x.transit(BreakerHalfOpen, now)
+	}
+
+	return x.state
+}
+
+func (x *Breaker) transit(to BreakerState, now time.Time) {
+	from := x.state
+	if from == to {
+		return
+	}
+
+	x.state = to
+	x.probes, x.passes = 0, 0
+	x.turns++
+
+	switch to {
+	case BreakerOpen:
+		x.openedAt = now
+	case BreakerClosed:
+		x.start, x.total, x.failures = now, 0, 0
+	}
+
+	if x.onChange != nil {
+		go x.onChange(x.driver, from, to)
+	}
+}
+
+type BreakerBuilder struct {
+	mu         sync.Mutex // ensures atomic writes; protects the following fields
+	window     time.Duration
+	requests   int
+	errorRate  float64
+	latency    time.Duration
+	cooldown   time.Duration
+	trials     int
+	classifier func(err error) bool
+	onChange   func(driver *Driver, from BreakerState, to BreakerState)
+}
+
+func (x *BreakerBuilder) Build() (*Breaker, error) {
+	if x.window < 0 {
+		return nil, errors.New("window can't be less than 0")
+	}
+
+	if x.requests < 0 {
+		return nil, errors.New("requests can't be less than 0")
+	}
+
+	if x.errorRate < 0 || x.errorRate > 1 {
+		return nil, fmt.Errorf("error rate %v must be between 0 and 1", x.errorRate)
+	}
+
+	if x.latency < 0 {
+		return nil, errors.New("latency can't be less than 0")
+	}
+
+	if x.cooldown < 0 {
+		return nil, errors.New("cooldown can't be less than 0")
+	}
+
+	if x.trials < 0 {
+		return nil, errors.New("trials can't be less than 0")
+	}
+
+	window := x.window
+	if window == 0 {
+		window = BreakerWindow
+	}
+
+	requests := x.requests
+	if requests == 0 {
+		requests = BreakerRequests
+	}
+
+	errorRate := x.errorRate
+	if errorRate == 0 {
+		errorRate = BreakerErrorRate
+	}
+
+	cooldown := x.cooldown
+	if cooldown == 0 {
+		cooldown = BreakerCooldown
+	}
+
+	trials := x.trials
+	if trials == 0 {
+		trials = BreakerTrials
+	}
+
+	classifier := x.classifier
+	if classifier == nil {
+		classifier = func(err error) bool {
+			return IsTransient(err) || errors.Is(err, context.DeadlineExceeded)
+		}
+	}
+
+	return &Breaker{
+		window:     window,
+		requests:   requests,
+		errorRate:  errorRate,
+		latency:    x.latency,
+		cooldown:   cooldown,
+		trials:     trials,
+		classifier: classifier,
+		onChange:   x.onChange,
+		start:      time.Now(),
+	}, nil
+}
+
+// 统计窗口，缺省：BreakerWindow
+func (x *BreakerBuilder) SetWindow(d time.Duration) *BreakerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.window = d
+	return x
+}
+
+// 窗口内最少调用数，缺省：BreakerRequests
+func (x *BreakerBuilder) SetRequests(n int) *BreakerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.requests = n
+	return x
+}
+
+// 失败率阈值，(0, 1]，缺省：BreakerErrorRate
+func (x *BreakerBuilder) SetErrorRate(f float64) *BreakerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.errorRate = f
+	return x
+}
+
+// 耗时阈值，超过时记为失败，缺省：0-不按耗时判定
+func (x *BreakerBuilder) SetLatency(d time.Duration) *BreakerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.latency = d
+	return x
+}
+
+// 打开后转为半开的等待时长，缺省：BreakerCooldown
+func (x *BreakerBuilder) SetCooldown(d time.Duration) *BreakerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.cooldown = d
+	return x
+}
+
+// 半开时的试探调用数，缺省：BreakerTrials
+func (x *BreakerBuilder) SetTrials(n int) *BreakerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.trials = n
+	return x
+}
+
+// 判定失败的错误，缺省：IsTransient或超时
+func (x *BreakerBuilder) SetClassifier(f func(err error) bool) *BreakerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.classifier = f
+	return x
+}
+
+// 状态变更回调，异步调用
+func (x *BreakerBuilder) SetOnStateChange(f func(driver *Driver, from BreakerState, to BreakerState)) *BreakerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.onChange = f
+	return x
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newBreakerDriver(t *testing.T, name string, b *BreakerBuilder) *Driver {
+	db := &DriverBuilder{}
+	d, err := db.SetName(name).SetSchema(&Schema{host: "127.0.0.1", port: Port, database: "test", username: "root"}).SetLazy(true).SetBreaker(b).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func TestBreaker(t *testing.T) {
+	setFakeResult("SELECT id FROM breaker", &fakeResult{columns: []string{"id"}, err: &fakeMySQLError{Number: 2013, Message: "Lost connection"}, fails: -1})
+
+	changes := make(chan BreakerState, 1)
+	b := &BreakerBuilder{}
+	b.SetRequests(2).SetCooldown(time.Hour).SetOnStateChange(func(driver *Driver, from BreakerState, to BreakerState) {
+		changes <- to
+	})
+
+	d := newBreakerDriver(t, "fake", b)
+	breaker := d.GetBreaker()
+
+	for i := 0; i < 2; i++ {
+		_, _, _ = Find(d, "SELECT id FROM breaker")
+	}
+
+	if got := breaker.GetState(); got != BreakerOpen {
+		t.Errorf("got %v; want %v", got, BreakerOpen)
+	}
+
+	if got := <-changes; got != BreakerOpen {
+		t.Errorf("got %v; want %v", got, BreakerOpen)
+	}
+
+	if _, got, _ := Find(d, "SELECT id FROM breaker"); !errors.Is(got, ErrCircuitOpen) {
+		t.Errorf("got %v; want %v", got, ErrCircuitOpen)
+	}
+
+	drivers := &Drivers{id: "test", readers: []*Driver{d}}
+	if _, got := drivers.GetReader(); !errors.Is(got, ErrCircuitOpen) {
+		t.Errorf("got %v; want %v", got, ErrCircuitOpen)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	lost := &fakeMySQLError{Number: 2013, Message: "Lost connection"}
+	setFakeResult("SELECT id FROM half_open", &fakeResult{columns: []string{"id"}, err: lost, fails: 2})
+
+	b := &BreakerBuilder{}
+	b.SetRequests(2).SetCooldown(20 * time.Millisecond).SetTrials(1)
+
+	d := newBreakerDriver(t, "fake", b)
+	breaker := d.GetBreaker()
+
+	for i := 0; i < 2; i++ {
+		_, _, _ = Find(d, "SELECT id FROM half_open")
+	}
+
+	if got := breaker.GetState(); got != BreakerOpen {
+		t.Fatalf("got %v; want %v", got, BreakerOpen)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if got := breaker.GetState(); got != BreakerHalfOpen {
+		t.Fatalf("got %v; want %v", got, BreakerHalfOpen)
+	}
+
+	if _, err, _ := Find(d, "SELECT id FROM half_open"); err != nil && !IsEmptyResult(err) {
+		t.Errorf("got %v; want nil", err)
+	}
+
+	if got := breaker.GetState(); got != BreakerClosed {
+		t.Errorf("got %v; want %v", got, BreakerClosed)
+	}
+}
+
+func TestBreakerReleaseProbe(t *testing.T) {
+	b := &BreakerBuilder{}
+	b.SetRequests(1).SetCooldown(time.Millisecond).SetTrials(1)
+
+	// 未注册的驱动，打开sql.DB失败，放行的试探调用未发起
+	d := newBreakerDriver(t, "unknown", b)
+	breaker := d.GetBreaker()
+	breaker.record(0, &fakeMySQLError{Number: 2013, Message: "Lost connection"})
+
+	time.Sleep(5 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if _, err := Exec(d, "UPDATE breaker SET id = 1"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("got %v; want open error", err)
+		}
+	}
+
+	if got := breaker.GetState(); got != BreakerHalfOpen {
+		t.Errorf("got %v; want %v", got, BreakerHalfOpen)
+	}
+
+	drivers := &Drivers{id: "test", readers: []*Driver{d}}
+	if _, got := drivers.GetReader(); got != nil {
+		t.Errorf("got %v; want nil", got)
+	}
+
+	if _, ok := breaker.allow(); !ok {
+		t.Fatalf("got false; want probe allowed")
+	}
+
+	if _, got := drivers.GetReader(); !errors.Is(got, ErrCircuitOpen) {
+		t.Errorf("got %v; want %v when no probe is left", got, ErrCircuitOpen)
+	}
+}
@@ -1,5 +1,7 @@
 package database
 
+import "time"
+
 const (
 	Charset           = "utf8mb4"
 	Collation         = "utf8mb4_general_ci"
@@ -11,4 +13,67 @@ const (
 	EmptyResult       = "empty result" // 查询结果空
 	AggregateAlias    = "aggregate"    // 统计字段别名
 	ShardingSeparator = "_"            // 拼接库名和分库数
+	InsertIdColumn    = "id"           // 不支持LastInsertId时，Insert经RETURNING返回的自增列
+)
+
+const (
+	MethodExec      = "Exec"      // 调用方法，写
+	MethodFind      = "Find"      // 调用方法，查询多行
+	MethodFirst     = "First"     // 调用方法，查询首行
+	MethodAggregate = "Aggregate" // 调用方法，统计
+	MethodOne       = "One"       // 调用方法，查询唯一一行
+	MethodEach      = "Each"      // 调用方法，逐行回调
+	MethodIterate   = "Iterate"   // 调用方法，逐行遍历
+)
+
+const (
+	DrainInterval = 10 * time.Millisecond // 等待进行中的调用结束的轮询间隔
+	RetireTimeout = 30 * time.Second      // 热加载后，等待旧Driver进行中的调用结束的超时时间，到期时直接关闭
+	WatchInterval = 5 * time.Second       // 监听配置文件变更的轮询间隔
+)
+
+const (
+	RetryAttempts = 3                     // 重试时，最多尝试次数，含首次
+	RetryBase     = 50 * time.Millisecond // 重试时，首次退避时长
+	RetryMax      = 1 * time.Second       // 重试时，最大退避时长
+)
+
+const (
+	BreakerWindow    = 10 * time.Second // 熔断统计窗口
+	BreakerRequests  = 20               // 窗口内最少调用数，不足时不熔断
+	BreakerErrorRate = 0.5              // 窗口内失败率达到时熔断
+	BreakerCooldown  = 5 * time.Second  // 熔断后，转为半开的等待时长
+	BreakerTrials    = 1                // 半开时，允许的试探调用数
+)
+
+const (
+	DecimalString = 1 // DECIMAL转为string，不丢失精度
+	DecimalRat    = 2 // DECIMAL转为*big.Rat
+)
+
+const (
+	EmptyInError = 1 // IN的切片参数为空时，返回错误
+	EmptyInNull  = 2 // IN的切片参数为空时，展开为IN (NULL)，不匹配任何行
+)
+
+const (
+	DuplicateOverwrite = 1 // 列名重复时，后面的列覆盖前面的列
+	DuplicateError     = 2 // 列名重复时，返回错误
+	DuplicateRename    = 3 // 列名重复时，第n个重命名为"列名_n"，如：id、id_2
+)
+
+const (
+	BatchMaxPacket = 4 << 20 // 批量插入时，单条sql的最大字节数，取MySQL 5.7的max_allowed_packet缺省值4MB（8.0起为64MB）
+)
+
+const (
+	UpsertUnknown   = 0 // 方言不支持区分插入或更新，如：SQLite、MySQL的多行
+	UpsertInserted  = 1 // 插入
+	UpsertUpdated   = 2 // 更新
+	UpsertUnchanged = 3 // 冲突但值未变，仅MySQL
+)
+
+const (
+	StatementSamples = 1024 // 每个sql指纹保留的耗时样本数，用于计算分位数
+	StatementLimit   = 1000 // 最多统计的sql指纹数，超出的不再统计
 )
@@ -1,29 +1,223 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Driver struct {
-	db     *sql.DB
-	name   string  // 驱动名，mysql、postgres、...
-	dsn    string  // data source name
-	schema *Schema // 配置
+	mu           sync.Mutex // ensures atomic opens; protects db, retired & closed
+	db           *sql.DB
+	interceptors []Interceptor // 拦截器，后于全局拦截器执行
+	retry        *Retry        // 重试策略，缺省：nil-不重试
+	breaker      *Breaker      // 熔断器，缺省：nil-不熔断
+	scanner      *Scanner      // Find、First等查询转换结果，缺省：nil-驱动返回的原值
+	name         string        // 驱动名，mysql、postgres、...
+	dsn          string        // data source name
+	schema       *Schema       // 配置
+	id           string        // 唯一标识，经Drivers或Sharding构建时设置
+	mode         int           // 角色，Write、Read、Backup，经Drivers或Sharding构建时设置
+	num          int           // 第n个库，经Sharding构建时设置，缺省：-1-不分库
+	inflight     atomic.Int64  // 进行中的调用数，热加载和关闭时等待归零
+	retired      bool          // 已停用，热加载替换或关闭时设置，不再开始新的调用
+	closed       bool          // 已关闭，不再打开sql.DB
 }
 
+// 打开sql.DB，懒加载时，首次使用才调用sql.Open
+func (x *Driver) Open() (*sql.DB, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.db != nil {
+		return x.db, nil
+	}
+
+	if x.closed {
+		return nil, newKindError(ErrShutdown, "driver has been closed")
+	}
+
+	db, err := sql.Open(x.name, x.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if x.schema != nil {
+		maxOpen := x.schema.GetMaxOpen()
+		if maxOpen > 0 {
+			db.SetMaxOpenConns(maxOpen)
+		}
+
+		maxIdle := x.schema.GetMaxIdle()
+		if maxIdle > 0 {
+			db.SetMaxIdleConns(maxIdle)
+		}
+
+		maxLifetime := x.schema.GetMaxLifetime()
+		if maxLifetime > 0 {
+			db.SetConnMaxLifetime(maxLifetime)
+		}
+	}
+
+	x.db = db
+	return db, nil
+}
+
+// 校验连接，懒加载时，先打开sql.DB
+func (x *Driver) Ping(ctx context.Context) error {
+	db, err := x.Open()
+	if err != nil {
+		return err
+	}
+
+	return db.PingContext(ctx)
+}
+
+// 开始一次调用，返回sql.DB和结束调用的函数，已停用时返回ErrShutdown
+// 与retire同在mu下判断和计数，retire之后drain不会漏掉进行中的调用
+func (x *Driver) acquire() (*sql.DB, func(), error) {
+	x.mu.Lock()
+	if x.retired {
+		x.mu.Unlock()
+		return nil, nil, newKindError(ErrShutdown, "driver has been retired")
+	}
+
+	x.inflight.Add(1)
+	x.mu.Unlock()
+
+	db, err := x.Open()
+	if err != nil {
+		x.inflight.Add(-1)
+		return nil, nil, err
+	}
+
+	return db, func() { x.inflight.Add(-1) }, nil
+}
+
+// 停用，不再开始新的调用，之后drain等待进行中的调用结束
+func (x *Driver) retire() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.retired = true
+}
+
+// 等待进行中的调用结束，且连接池中使用中的连接数归零
+func (x *Driver) drain(ctx context.Context) error {
+	if !x.busy() {
+		return nil
+	}
+
+	ticker := time.NewTicker(DrainInterval)
+	defer ticker.Stop()
+
+	for x.busy() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+func (x *Driver) busy() bool {
+	if x.inflight.Load() > 0 {
+		return true
+	}
+
+	x.mu.Lock()
+	db := x.db
+	x.mu.Unlock()
+
+	return db != nil && db.Stats().InUse > 0
+}
+
+// 等待进行中的调用结束后关闭，ctx到期时直接关闭
+func (x *Driver) Shutdown(ctx context.Context) error {
+	x.retire()
+	drainErr := x.drain(ctx)
+	return errors.Join(drainErr, x.Close())
+}
+
+// 标注唯一标识、角色和第n个库
+func (x *Driver) label(id string, mode int, num int) *Driver {
+	x.id = id
+	x.mode = mode
+	x.num = num
+	return x
+}
+
+// 配置指纹，驱动名、dsn和连接池配置均相同时，热加载复用该Driver，慢查询阈值等不影响连接的配置原地替换
+func (x *Driver) key() string {
+	return schemaKey(x.name, x.dsn, x.GetSchema())
+}
+
+// 关闭sql.DB，关闭后懒加载也不再打开
 func (x *Driver) Close() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.closed = true
+
 	if x.db != nil {
-		return x.db.Close()
+		db := x.db
+		x.db = nil
+		return db.Close()
 	} else {
 		return nil
 	}
 }
 
+// 懒加载时，首次调用打开sql.DB，打开失败返回nil
 func (x *Driver) GetDb() *sql.DB {
-	return x.db
+	db, _ := x.Open()
+	return db
+}
+
+// 进行中的调用数，仅统计经Exec、Find、First等发起的调用
+func (x *Driver) GetInflight() int64 {
+	return x.inflight.Load()
+}
+
+func (x *Driver) GetId() string {
+	return x.id
+}
+
+func (x *Driver) GetMode() int {
+	return x.mode
+}
+
+func (x *Driver) GetNum() int {
+	return x.num
+}
+
+func (x *Driver) GetRetry() *Retry {
+	return x.retry
+}
+
+func (x *Driver) GetBreaker() *Breaker {
+	return x.breaker
+}
+
+// 按驱动名取方言
+func (x *Driver) GetDialect() Dialect {
+	return GetDialect(x.name)
+}
+
+func (x *Driver) GetScanner() *Scanner {
+	return x.scanner
+}
+
+// 是否可选用，熔断器打开时不可选用
+func (x *Driver) available() bool {
+	return x.breaker == nil || x.breaker.available()
 }
 
 func (x *Driver) GetName() string {
@@ -35,14 +229,39 @@ func (x *Driver) GetDsn() string {
 }
 
 func (x *Driver) GetSchema() *Schema {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
 	return x.schema
 }
 
+// 热加载复用该Driver时，替换为新配置，如：慢查询阈值
+func (x *Driver) setSchema(s *Schema) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.schema = s
+}
+
+func schemaKey(name string, dsn string, s *Schema) string {
+	if s == nil {
+		return name + "|" + dsn
+	}
+
+	return fmt.Sprintf("%s|%s|%d|%d|%d", name, dsn, s.GetMaxOpen(), s.GetMaxIdle(), s.GetMaxLifetime())
+}
+
 type DriverBuilder struct {
-	mu     sync.Mutex // ensures atomic writes; protects the following fields
-	name   string
-	schema *Schema
-	joiner func(s *Schema) string // dsn拼接函数
+	mu           sync.Mutex // ensures atomic writes; protects the following fields
+	name         string
+	schema       *Schema
+	joiner       func(s *Schema) string // dsn拼接函数，缺省：方言的Dsn
+	lazy         bool                   // 懒加载，首次使用才调用sql.Open，缺省：false
+	pingTimeout  time.Duration          // Build时校验连接的超时时间，缺省：0-不校验
+	interceptors []Interceptor          // 拦截器
+	retry        *Retry                 // 重试策略
+	breaker      *BreakerBuilder        // 熔断器，每个Driver一个
+	scanner      *Scanner               // 查询结果转换
 }
 
 func (x *DriverBuilder) Build() (*Driver, error) {
@@ -54,42 +273,57 @@ func (x *DriverBuilder) Build() (*Driver, error) {
 		return nil, errors.New("schema can't be nil")
 	}
 
+	if x.lazy && x.pingTimeout > 0 {
+		return nil, errors.New("lazy and ping timeout can't be both set")
+	}
+
 	joiner := x.joiner
 	if joiner == nil {
-		joiner = DsnJoiner
+		joiner = GetDialect(x.name).Dsn
 	}
 
-	dsn := joiner(x.schema)
+	schema := x.schema.withPort(GetDialect(x.name))
+	dsn := joiner(schema)
 	if dsn == "" {
 		return nil, errors.New("dsn can't be empty")
 	}
 
-	db, err := sql.Open(x.name, dsn)
-	if err != nil {
-		return nil, err
+	driver := &Driver{
+		name:         x.name,
+		dsn:          dsn,
+		schema:       schema,
+		num:          -1,
+		interceptors: append([]Interceptor{}, x.interceptors...),
+		retry:        x.retry,
+		scanner:      x.scanner,
 	}
 
-	maxOpen := x.schema.GetMaxOpen()
-	if maxOpen > 0 {
-		db.SetMaxOpenConns(maxOpen)
+	if x.breaker != nil {
+		breaker, err := x.breaker.Build()
+		if err != nil {
+			return nil, err
+		}
+
+		breaker.driver = driver
+		driver.breaker = breaker
+	}
+
+	if x.lazy {
+		return driver, nil
 	}
 
-	maxIdle := x.schema.GetMaxIdle()
-	if maxIdle > 0 {
-		db.SetMaxIdleConns(maxIdle)
+	if _, err := driver.Open(); err != nil {
+		return nil, err
 	}
 
-	maxLifetime := x.schema.GetMaxLifetime()
-	if maxLifetime > 0 {
-		db.SetConnMaxLifetime(maxLifetime)
+	if x.pingTimeout > 0 {
+		if err := pingDrivers(x.pingTimeout, map[string][]*Driver{"": {driver}}); err != nil {
+			_ = driver.Close()
+			return nil, err
+		}
 	}
 
-	return &Driver{
-		db:     db,
-		name:   x.name,
-		dsn:    dsn,
-		schema: x.schema,
-	}, nil
+	return driver, nil
 }
 
 func (x *DriverBuilder) SetName(s string) *DriverBuilder {
@@ -117,3 +351,54 @@ func (x *DriverBuilder) SetJoiner(f func(s *Schema) string) *DriverBuilder {
 	x.joiner = f
 	return x
 }
+
+func (x *DriverBuilder) SetLazy(b bool) *DriverBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.lazy = b
+	return x
+}
+
+func (x *DriverBuilder) SetPingTimeout(d time.Duration) *DriverBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.pingTimeout = d
+	return x
+}
+
+func (x *DriverBuilder) AddInterceptor(i Interceptor) *DriverBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if i != nil {
+		x.interceptors = append(x.interceptors, i)
+	}
+
+	return x
+}
+
+func (x *DriverBuilder) SetRetry(r *Retry) *DriverBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.retry = r
+	return x
+}
+
+func (x *DriverBuilder) SetBreaker(b *BreakerBuilder) *DriverBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.breaker = b
+	return x
+}
+
+func (x *DriverBuilder) SetScanner(s *Scanner) *DriverBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.scanner = s
+	return x
+}
@@ -1,6 +1,10 @@
 package database
 
-import "testing"
+import (
+	"errors"
+	"testing"
+	"time"
+)
 
 func TestDriverBuilder(t *testing.T) {
 	b := &DriverBuilder{}
@@ -17,3 +21,95 @@ func TestDriverBuilder(t *testing.T) {
 		t.Errorf("got %q; want %q", got2, want2)
 	}
 }
+
+func TestDriverBuilderLazy(t *testing.T) {
+	schema := &Schema{host: "127.0.0.1", port: Port, database: "test", username: "root"}
+
+	b := &DriverBuilder{}
+	b.SetName("unknown").SetSchema(schema)
+	_, got := b.Build()
+	if got == nil {
+		t.Errorf("got nil; want error")
+	}
+
+	b.SetLazy(true)
+	driver, got2 := b.Build()
+	if got2 != nil {
+		t.Errorf("got %q; want nil", got2)
+	}
+
+	_, got3 := driver.Open()
+	if got3 == nil {
+		t.Errorf("got nil; want error")
+	}
+
+	b.SetPingTimeout(time.Second)
+	_, got4 := b.Build()
+	want4 := "lazy and ping timeout can't be both set"
+	if got4 == nil || got4.Error() != want4 {
+		t.Errorf("got %v; want %q", got4, want4)
+	}
+}
+
+func TestDriverClose(t *testing.T) {
+	schema := &Schema{host: "127.0.0.1", port: Port, database: "test", username: "root"}
+
+	b := &DriverBuilder{}
+	driver, err := b.SetName("fake").SetSchema(schema).SetLazy(true).Build()
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+
+	if got := driver.GetDb(); got == nil {
+		t.Fatalf("got nil; want db")
+	}
+
+	if got := driver.Close(); got != nil {
+		t.Errorf("got %q; want nil", got)
+	}
+
+	_, got2 := driver.Open()
+	if !errors.Is(got2, ErrShutdown) {
+		t.Errorf("got %v; want ErrShutdown", got2)
+	}
+}
+
+func TestDriversBuilderPing(t *testing.T) {
+	b := &DriversBuilder{}
+	_ = b.SetId("test")
+	_ = b.SetName("fake")
+	_ = b.AddWriter(&Schema{host: "127.0.0.1", port: Port, database: "test", username: "root"})
+	_ = b.AddReader(&Schema{host: "127.0.0.2", port: Port, database: "unreachable", username: "root"})
+	_ = b.AddBackup(&Schema{host: "127.0.0.3", port: Port, database: "unreachable", username: "root"})
+	b.SetPingTimeout(time.Second)
+
+	_, got := b.Build()
+	if got == nil {
+		t.Fatalf("got nil; want error")
+	}
+
+	want := "ping backup 127.0.0.3:3306/unreachable: fake: connection refused\nping reader 127.0.0.2:3306/unreachable: fake: connection refused"
+	if got.Error() != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestShardLabel(t *testing.T) {
+	tests := []struct {
+		num  int
+		size int
+		want string
+	}{
+		{0, 1, "shard 0"},
+		{2, 10, "shard 2"},
+		{2, 11, "shard 02"},
+		{10, 11, "shard 10"},
+		{7, 1000, "shard 007"},
+	}
+
+	for _, tt := range tests {
+		if got := shardLabel(tt.num, tt.size); got != tt.want {
+			t.Errorf("shardLabel(%d, %d) = %q; want %q", tt.num, tt.size, got, tt.want)
+		}
+	}
+}
@@ -1,75 +1,147 @@
 package database
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Drivers struct {
-	id      string    // 唯一标识
-	writers []*Driver // 主库列表
-	readers []*Driver // 从库列表
-	backups []*Driver // 备库列表
+	mu       sync.RWMutex // ensures atomic reloads; protects the following lists
+	reloadMu sync.Mutex   // ensures serial reloads & shutdown
+	id       string       // 唯一标识
+	writers  []*Driver    // 主库列表
+	readers  []*Driver    // 从库列表
+	backups  []*Driver    // 备库列表
+	closed   bool         // 已关闭，不再分配Driver
+	retry    *Retry       // 重试策略，重试时换一个同角色的Driver
 }
 
-// 主库列表，随机取一个Driver
+// 主库列表，随机取一个Driver，跳过熔断的Driver
 func (x *Drivers) GetWriter() (*Driver, error) {
-	if x.writers == nil {
-		return nil, errors.New("writers can't be nil")
+	return x.pick(Write, nil)
+}
+
+// 从库列表，随机取一个Driver，跳过熔断的Driver
+func (x *Drivers) GetReader() (*Driver, error) {
+	return x.pick(Read, nil)
+}
+
+// 备库列表，随机取一个Driver，跳过熔断的Driver
+func (x *Drivers) GetBackup() (*Driver, error) {
+	return x.pick(Backup, nil)
+}
+
+// 按角色随机取一个Driver，mode：Write、Read、Backup
+func (x *Drivers) GetDriver(mode int) (*Driver, error) {
+	switch mode {
+	case Write:
+		return x.GetWriter()
+	case Read:
+		return x.GetReader()
+	case Backup:
+		return x.GetBackup()
+	default:
+		return nil, CheckMode(mode)
 	}
+}
 
-	size := len(x.writers)
-	if size == 0 {
-		return nil, errors.New("writers can't be empty")
+// 按角色取Driver执行fn，临时错误时按重试策略换一个同角色的Driver重试
+// 读总是允许重试，写需WithIdempotent(ctx)标记幂等
+func (x *Drivers) Do(ctx context.Context, mode int, fn func(driver *Driver) error) error {
+	if fn == nil {
+		return errors.New("fn can't be nil")
 	}
 
-	num := 0
-	if size > 1 {
-		num = rand.Intn(size)
+	if x.retry == nil || (mode == Write && !IsIdempotent(ctx)) {
+		driver, err := x.GetDriver(mode)
+		if err != nil {
+			return err
+		}
+
+		return fn(driver)
 	}
 
-	return x.writers[num], nil
+	return x.retry.Do(ctx, func(tried []*Driver) (*Driver, error) {
+		return x.pick(mode, tried)
+	}, fn)
 }
 
-// 从库列表，随机取一个Driver
-func (x *Drivers) GetReader() (*Driver, error) {
-	if x.readers == nil {
-		return nil, errors.New("readers can't be nil")
+// 按角色随机取一个未尝试过且未熔断的Driver，全部尝试过时，不再排除尝试过的
+func (x *Drivers) pick(mode int, tried []*Driver) (*Driver, error) {
+	if err := CheckMode(mode); err != nil {
+		return nil, err
 	}
 
-	size := len(x.readers)
-	if size == 0 {
-		return nil, errors.New("readers can't be empty")
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if x.closed {
+		return nil, newKindError(ErrShutdown, "drivers has been shut down")
 	}
 
-	num := 0
-	if size > 1 {
-		num = rand.Intn(size)
+	var list []*Driver
+	switch mode {
+	case Write:
+		list = x.writers
+	case Read:
+		list = x.readers
+	case Backup:
+		list = x.backups
 	}
 
-	return x.readers[num], nil
-}
+	if list == nil {
+		return nil, newKindError(ErrNoDriver, "%ss can't be nil", ModeName(mode))
+	}
 
-// 备库列表，随机取一个Driver
-func (x *Drivers) GetBackup() (*Driver, error) {
-	if x.backups == nil {
-		return nil, errors.New("backups can't be nil")
+	if len(list) == 0 {
+		return nil, newKindError(ErrNoDriver, "%ss can't be empty", ModeName(mode))
+	}
+
+	var available []*Driver
+	for _, d := range list {
+		if d.available() {
+			available = append(available, d)
+		}
 	}
 
-	size := len(x.backups)
-	if size == 0 {
-		return nil, errors.New("backups can't be empty")
+	if len(available) == 0 {
+		return nil, ErrCircuitOpen
+	}
+
+	var candidates []*Driver
+	for _, d := range available {
+		skip := false
+		for _, t := range tried {
+			if d == t {
+				skip = true
+				break
+			}
+		}
+
+		if !skip {
+			candidates = append(candidates, d)
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = available
 	}
 
 	num := 0
-	if size > 1 {
+	if size := len(candidates); size > 1 {
 		num = rand.Intn(size)
 	}
 
-	return x.backups[num], nil
+	return candidates[num], nil
+}
+
+func (x *Drivers) GetRetry() *Retry {
+	return x.retry
 }
 
 func (x *Drivers) GetId() string {
@@ -77,19 +149,43 @@ func (x *Drivers) GetId() string {
 }
 
 func (x *Drivers) GetWriters() []*Driver {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
 	return x.writers
 }
 
 func (x *Drivers) GetReaders() []*Driver {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
 	return x.readers
 }
 
 func (x *Drivers) GetBackups() []*Driver {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
 	return x.backups
 }
 
+// 全部Driver，含主库、从库和备库
+func (x *Drivers) all() []*Driver {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var r []*Driver
+	r = append(r, x.writers...)
+	r = append(r, x.readers...)
+	r = append(r, x.backups...)
+	return r
+}
+
 // 关闭全部sql.DB
 func (x *Drivers) Close() []error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
 	var r []error
 
 	if x.writers != nil {
@@ -120,13 +216,19 @@ func (x *Drivers) Close() []error {
 }
 
 type DriversBuilder struct {
-	mu        sync.Mutex // ensures atomic writes; protects the following fields
-	id        string
-	name      string                 // 驱动名，mysql、postgres、...
-	dsnJoiner func(s *Schema) string // dsn拼接函数
-	writers   []*Schema              // 主库列表
-	readers   []*Schema              // 从库列表
-	backups   []*Schema              // 备库列表
+	mu           sync.Mutex // ensures atomic writes; protects the following fields
+	id           string
+	name         string                 // 驱动名，mysql、postgres、...
+	dsnJoiner    func(s *Schema) string // dsn拼接函数
+	writers      []*Schema              // 主库列表
+	readers      []*Schema              // 从库列表
+	backups      []*Schema              // 备库列表
+	lazy         bool                   // 懒加载，首次使用才调用sql.Open，缺省：false
+	pingTimeout  time.Duration          // Build时并发校验全部连接的超时时间，缺省：0-不校验
+	interceptors []Interceptor          // 拦截器，用于全部Driver
+	retry        *Retry                 // 重试策略，重试时换一个同角色的Driver
+	breaker      *BreakerBuilder        // 熔断器，每个Driver一个
+	scanner      *Scanner               // 查询结果转换，用于全部Driver
 }
 
 func (x *DriversBuilder) Build() (*Drivers, error) {
@@ -138,27 +240,27 @@ func (x *DriversBuilder) Build() (*Drivers, error) {
 		return nil, errors.New("name can't be empty")
 	}
 
-	dsnJoiner := x.dsnJoiner
-	if dsnJoiner == nil {
-		dsnJoiner = DsnJoiner
+	if x.lazy && x.pingTimeout > 0 {
+		return nil, errors.New("lazy and ping timeout can't be both set")
 	}
 
-	builder := DriverBuilder{}
-	builder.SetName(x.name).SetJoiner(dsnJoiner)
+	builder := x.driverBuilder()
 
 	var w []*Driver
 	if x.writers != nil {
 		for _, schema := range x.writers {
 			if schema == nil {
+				closeDrivers(w)
 				return nil, errors.New("writer's schema can't be nil")
 			}
 
 			driver, err := builder.SetSchema(schema).Build()
 			if err != nil {
+				closeDrivers(w)
 				return nil, err
 			}
 
-			w = append(w, driver)
+			w = append(w, driver.label(x.id, Write, -1))
 		}
 	}
 
@@ -166,15 +268,17 @@ func (x *DriversBuilder) Build() (*Drivers, error) {
 	if x.readers != nil {
 		for _, schema := range x.readers {
 			if schema == nil {
+				closeDrivers(w, r)
 				return nil, errors.New("reader's schema can't be nil")
 			}
 
 			driver, err := builder.SetSchema(schema).Build()
 			if err != nil {
+				closeDrivers(w, r)
 				return nil, err
 			}
 
-			r = append(r, driver)
+			r = append(r, driver.label(x.id, Read, -1))
 		}
 	}
 
@@ -182,20 +286,29 @@ func (x *DriversBuilder) Build() (*Drivers, error) {
 	if x.backups != nil {
 		for _, schema := range x.backups {
 			if schema == nil {
+				closeDrivers(w, r, b)
 				return nil, errors.New("backup's schema can't be nil")
 			}
 
 			driver, err := builder.SetSchema(schema).Build()
 			if err != nil {
+				closeDrivers(w, r, b)
 				return nil, err
 			}
 
-			b = append(b, driver)
+			b = append(b, driver.label(x.id, Backup, -1))
 		}
 	}
 
 	if w == nil && r == nil && b == nil {
-		return nil, errors.New("no driver, writer & reader & backup is nil")
+		return nil, newKindError(ErrNoDriver, "no driver, writer & reader & backup is nil")
+	}
+
+	if x.pingTimeout > 0 {
+		if err := pingDrivers(x.pingTimeout, map[string][]*Driver{ModeName(Write): w, ModeName(Read): r, ModeName(Backup): b}); err != nil {
+			closeDrivers(w, r, b)
+			return nil, err
+		}
 	}
 
 	return &Drivers{
@@ -203,9 +316,25 @@ func (x *DriversBuilder) Build() (*Drivers, error) {
 		writers: w,
 		readers: r,
 		backups: b,
+		retry:   x.retry,
 	}, nil
 }
 
+func (x *DriversBuilder) driverBuilder() *DriverBuilder {
+	dsnJoiner := x.dsnJoiner
+	if dsnJoiner == nil {
+		dsnJoiner = GetDialect(x.name).Dsn
+	}
+
+	builder := &DriverBuilder{}
+	builder.SetName(x.name).SetJoiner(dsnJoiner).SetLazy(x.lazy).SetBreaker(x.breaker).SetScanner(x.scanner)
+	for _, i := range x.interceptors {
+		builder.AddInterceptor(i)
+	}
+
+	return builder
+}
+
 func (x *DriversBuilder) SetId(s string) error {
 	if s = strings.TrimSpace(s); s == "" {
 		return errors.New("id can't be empty")
@@ -254,6 +383,57 @@ func (x *DriversBuilder) SetDsnJoiner(f func(s *Schema) string) *DriversBuilder
 	return x
 }
 
+func (x *DriversBuilder) SetLazy(b bool) *DriversBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.lazy = b
+	return x
+}
+
+func (x *DriversBuilder) SetPingTimeout(d time.Duration) *DriversBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.pingTimeout = d
+	return x
+}
+
+func (x *DriversBuilder) SetRetry(r *Retry) *DriversBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.retry = r
+	return x
+}
+
+func (x *DriversBuilder) SetBreaker(b *BreakerBuilder) *DriversBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.breaker = b
+	return x
+}
+
+func (x *DriversBuilder) SetScanner(s *Scanner) *DriversBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.scanner = s
+	return x
+}
+
+func (x *DriversBuilder) AddInterceptor(i Interceptor) *DriversBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if i != nil {
+		x.interceptors = append(x.interceptors, i)
+	}
+
+	return x
+}
+
 func (x *DriversBuilder) AddWriter(s *Schema) error {
 	if s == nil {
 		return errors.New("schema can't be nil")
@@ -3,13 +3,9 @@ package database
 import "errors"
 
 func NewEmptyResult() error {
-	return errors.New(EmptyResult)
+	return ErrEmptyResult
 }
 
 func IsEmptyResult(err error) bool {
-	if err == nil {
-		return false
-	} else {
-		return EmptyResult == err.Error()
-	}
+	return errors.Is(err, ErrEmptyResult)
 }
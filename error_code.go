@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+)
+
+// MySQL错误码，如：*mysql.MySQLError的Number，不存在时返回0
+func errorNumber(err error) int {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				break
+			}
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+
+		f := v.FieldByName("Number")
+		if !f.IsValid() {
+			continue
+		}
+
+		switch f.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int(f.Uint())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return int(f.Int())
+		}
+	}
+
+	return 0
+}
+
+// PostgreSQL错误码，如：*pgconn.PgError、*pq.Error的SQLState()，不存在时返回""
+func sqlState(err error) string {
+	var e interface{ SQLState() string }
+	if errors.As(err, &e) {
+		return e.SQLState()
+	}
+
+	return ""
+}
+
+// *QueryError的Driver方言，不存在时返回nil
+func errorDialect(err error) Dialect {
+	var e *QueryError
+	if errors.As(err, &e) {
+		return e.dialect
+	}
+
+	return nil
+}
+
+// 是否是唯一键冲突，MySQL：1062、1586，PostgreSQL：23505，SQLite：UNIQUE constraint failed
+func IsDuplicateKey(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return MySQLDialect{}.IsDuplicateKey(err) || PostgresDialect{}.IsDuplicateKey(err) || SqliteDialect{}.IsDuplicateKey(err)
+}
+
+// 是否是死锁，MySQL：1213，PostgreSQL：40P01
+func IsDeadlock(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return MySQLDialect{}.IsDeadlock(err) || PostgresDialect{}.IsDeadlock(err)
+}
+
+// 是否是超时，MySQL：1205锁等待超时、3024执行超时，PostgreSQL：57014取消、55P03锁不可用，ctx到期或网络超时
+// *QueryError仅按其Driver的方言判断错误码
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	candidates := []Dialect{MySQLDialect{}, PostgresDialect{}, SqliteDialect{}}
+	if d := errorDialect(err); d != nil {
+		candidates = []Dialect{d}
+	}
+
+	for _, d := range candidates {
+		if d.IsTimeout(err) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
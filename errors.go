@@ -0,0 +1,88 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrEmptyResult         = errors.New(EmptyResult)                // 查询结果空
+	ErrNilDriver           = errors.New("driver can't be nil")      // Driver为nil
+	ErrNoDriver            = errors.New("no driver")                // 角色下无可用的Driver
+	ErrNotFound            = errors.New("not found")                // 唯一标识不存在
+	ErrShardOutOfRange     = errors.New("shard out of range")       // 第n个库超出分库数
+	ErrCircuitOpen         = errors.New("circuit breaker is open")  // 熔断器打开
+	ErrShutdown            = errors.New("has been shut down")       // 已关闭，不再分配Driver
+	ErrInvalidProfile      = errors.New("invalid profile")          // 配置项无法解析
+	ErrMultipleRows        = errors.New("multiple rows")            // 期望一行，查询结果多于一行
+	ErrTooManyPlaceholders = errors.New("too many placeholders")    // 占位符数超出驱动的上限
+	ErrUnexpectedRows      = errors.New("unexpected affected rows") // 影响行数与WithExpectedRows不符
+)
+
+// 关闭rows的错误并入主错误，closeErr为nil时原样返回err
+func joinClose(err error, closeErr error) error {
+	if closeErr == nil {
+		return err
+	}
+
+	return errors.Join(err, closeErr)
+}
+
+// 保留原错误信息，同时可经errors.Is判断所属的哨兵错误
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (x *kindError) Error() string {
+	return x.msg
+}
+
+func (x *kindError) Unwrap() error {
+	return x.kind
+}
+
+func newKindError(kind error, format string, args ...interface{}) error {
+	return &kindError{msg: fmt.Sprintf(format, args...), kind: kind}
+}
+
+// 配置项无法解析，如：max_open = abc
+type ProfileError struct {
+	Key   string // 配置项，如：ProfileMaxOpen
+	Value string // 原始值
+	Err   error  // 解析错误
+}
+
+func (x *ProfileError) Error() string {
+	return fmt.Sprintf(`profile %s = "%s": %v`, x.Key, x.Value, x.Err)
+}
+
+func (x *ProfileError) Unwrap() []error {
+	return []error{ErrInvalidProfile, x.Err}
+}
+
+// 调用失败，标注Driver的唯一标识、角色和sql
+type QueryError struct {
+	DriverId string // 唯一标识，未经Drivers或Sharding构建时为空
+	Role     string // 角色，writer、reader、backup，未经Drivers或Sharding构建时为空
+	Query    string // 原始sql，错误信息中输出脱敏后的sql
+	Err      error
+	dialect  Dialect // 脱敏sql时按该方言切分，缺省：MySQL
+}
+
+func (x *QueryError) Error() string {
+	on := ""
+	if x.DriverId != "" {
+		on = fmt.Sprintf(` on "%s"`, x.DriverId)
+	}
+
+	if x.Role != "" {
+		on += " " + x.Role
+	}
+
+	return fmt.Sprintf(`query "%s"%s: %v`, sanitize(x.dialect, x.Query), on, x.Err)
+}
+
+func (x *QueryError) Unwrap() error {
+	return x.Err
+}
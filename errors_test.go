@@ -0,0 +1,36 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	_, err := NewProfile(map[string]string{ProfileMaxOpen: "abc"})
+
+	var profileErr *ProfileError
+	if !errors.As(err, &profileErr) || profileErr.Key != ProfileMaxOpen || profileErr.Value != "abc" {
+		t.Errorf("got %v; want *ProfileError of %q", err, ProfileMaxOpen)
+	}
+
+	if !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("got %v; want %v", err, ErrInvalidProfile)
+	}
+
+	s := &Sharding{id: "order", size: 2, drivers: []*Driver{{}, {}}}
+	_, err2 := s.GetDriver(2)
+	want2 := "num 2 can't be greater or equal than size 2"
+	if !errors.Is(err2, ErrShardOutOfRange) || err2.Error() != want2 {
+		t.Errorf("got %v; want %q", err2, want2)
+	}
+
+	err3 := &QueryError{DriverId: "user", Role: "writer", Query: "INSERT INTO user (id) VALUES (1)", Err: &fakeMySQLError{Number: 1062, Message: "Duplicate entry"}}
+	want3 := `query "INSERT INTO user (id) VALUES (?)" on "user" writer: Duplicate entry`
+	if err3.Error() != want3 {
+		t.Errorf("got %q; want %q", err3.Error(), want3)
+	}
+
+	if !IsDuplicateKey(err3) || IsDeadlock(err3) || IsTimeout(err3) {
+		t.Errorf("got duplicate %v, deadlock %v, timeout %v; want true, false, false", IsDuplicateKey(err3), IsDeadlock(err3), IsTimeout(err3))
+	}
+}
@@ -1,19 +1,29 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"errors"
 )
 
 func Exec(driver *Driver, query string, args ...interface{}) (sql.Result, error) {
-	if driver == nil {
-		return nil, errors.New("driver can't be nil")
-	}
+	return ExecContext(context.Background(), driver, query, args...)
+}
+
+// 写，Driver设置重试策略且WithIdempotent(ctx)标记幂等时，临时错误自动重试
+func ExecContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (result sql.Result, err error) {
+	err = run(ctx, driver, MethodExec, query, args, func(db *sql.DB, c *Call) error {
+		r, err := db.ExecContext(c.ctx, c.query, c.args...)
+		if err != nil {
+			return err
+		}
+
+		if n, err := r.RowsAffected(); err == nil {
+			c.rows = n
+		}
 
-	db := driver.GetDb()
-	if db == nil {
-		return nil, errors.New("db can't be nil")
-	}
+		result = r
+		return nil
+	})
 
-	return db.Exec(query, args...)
+	return
 }
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+type expectedRowsKey struct{}
+
+// 要求影响行数恰为n，不符时返回ErrUnexpectedRows，用于乐观锁，如：UpdateContext(WithExpectedRows(ctx, 1), driver, "UPDATE ... WHERE version = ?", 3)
+func WithExpectedRows(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, expectedRowsKey{}, n)
+}
+
+func GetExpectedRows(ctx context.Context) (int64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	n, ok := ctx.Value(expectedRowsKey{}).(int64)
+	return n, ok
+}
+
+// 返回影响行数
+func Update(driver *Driver, query string, args ...interface{}) (int64, error) {
+	return UpdateContext(context.Background(), driver, query, args...)
+}
+
+func UpdateContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (int64, error) {
+	return affectedContext(ctx, driver, query, args)
+}
+
+// 返回影响行数
+func Delete(driver *Driver, query string, args ...interface{}) (int64, error) {
+	return DeleteContext(context.Background(), driver, query, args...)
+}
+
+func DeleteContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (int64, error) {
+	return affectedContext(ctx, driver, query, args)
+}
+
+func affectedContext(ctx context.Context, driver *Driver, query string, args []interface{}) (int64, error) {
+	r, err := ExecContext(ctx, driver, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return 0, wrapQueryError(driver, query, err)
+	}
+
+	return n, checkExpectedRows(ctx, driver, query, n)
+}
+
+// 影响行数与WithExpectedRows不符时返回错误
+func checkExpectedRows(ctx context.Context, driver *Driver, query string, n int64) error {
+	if want, ok := GetExpectedRows(ctx); ok && n != want {
+		return wrapQueryError(driver, query, newKindError(ErrUnexpectedRows, "affected %d rows, want %d", n, want))
+	}
+
+	return nil
+}
+
+// 执行INSERT ... RETURNING，读取首行首列，rows为返回的行数
+func queryId(ctx context.Context, driver *Driver, query string, args []interface{}) (id int64, rows int64, err error) {
+	err = run(ctx, driver, MethodExec, query, args, func(db *sql.DB, c *Call) error {
+		id, rows = 0, 0
+
+		r, err := db.QueryContext(c.ctx, c.query, c.args...)
+		if err != nil {
+			return err
+		}
+
+		for r.Next() {
+			if rows == 0 {
+				if err := scanFirst(r, &id); err != nil {
+					return joinClose(err, r.Close())
+				}
+			}
+
+			rows++
+		}
+
+		c.rows = rows
+		return joinClose(r.Err(), r.Close())
+	})
+
+	return
+}
+
+// 读取首列，其余列忽略
+func scanFirst(rows *sql.Rows, dest interface{}) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	dests := make([]interface{}, len(columns))
+	for i := range dests {
+		dests[i] = new(interface{})
+	}
+
+	if len(dests) > 0 {
+		dests[0] = dest
+	}
+
+	return rows.Scan(dests...)
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"strings"
+)
+
+// 返回自增id，方言不支持LastInsertId时（如PostgreSQL）追加RETURNING InsertIdColumn，已含RETURNING时不追加
+// RETURNING无返回行时（如ON CONFLICT DO NOTHING）返回0和empty result，设置WithExpectedRows时按其校验
+func Insert(driver *Driver, query string, args ...interface{}) (int64, error) {
+	return InsertContext(context.Background(), driver, query, args...)
+}
+
+func InsertContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (int64, error) {
+	if driver == nil {
+		return 0, ErrNilDriver
+	}
+
+	d := driver.GetDialect()
+	if !d.SupportsLastInsertId() && d.SupportsReturning() {
+		q := query
+		if !hasReturning(d, query) {
+			q = trimTail(d, query) + " RETURNING " + d.Quote(InsertIdColumn)
+		}
+
+		id, n, err := queryId(ctx, driver, q, args)
+		if err != nil {
+			return 0, err
+		}
+
+		if _, ok := GetExpectedRows(ctx); ok {
+			return id, checkExpectedRows(ctx, driver, q, n)
+		}
+
+		if n == 0 {
+			return 0, NewEmptyResult()
+		}
+
+		return id, nil
+	}
+
+	r, err := ExecContext(ctx, driver, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := r.LastInsertId()
+	if err != nil {
+		return 0, wrapQueryError(driver, query, err)
+	}
+
+	if _, ok := GetExpectedRows(ctx); ok {
+		n, err := r.RowsAffected()
+		if err != nil {
+			return 0, wrapQueryError(driver, query, err)
+		}
+
+		if err := checkExpectedRows(ctx, driver, query, n); err != nil {
+			return id, err
+		}
+	}
+
+	return id, nil
+}
+
+// 去掉末尾的空白、注释和分号，避免追加的RETURNING被行注释吞掉
+func trimTail(d Dialect, query string) string {
+	tokens := lexSql(d, query)
+
+	end := len(tokens)
+	for ; end > 0; end-- {
+		t := tokens[end-1]
+		if t.kind != tokenSpace && t.kind != tokenComment && t.text != ";" {
+			break
+		}
+	}
+
+	var b strings.Builder
+	for _, t := range tokens[:end] {
+		b.WriteString(t.text)
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func hasReturning(d Dialect, query string) bool {
+	for _, t := range lexSql(d, query) {
+		if t.kind == tokenWord && strings.EqualFold(t.text, "RETURNING") {
+			return true
+		}
+	}
+
+	return false
+}
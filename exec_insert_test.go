@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestInsert(t *testing.T) {
+	setFakeResult("INSERT INTO user (name) VALUES (?)", &fakeResult{affected: 1, insertId: 9})
+	setFakeResult(`INSERT INTO user (name) VALUES ($1) RETURNING "id"`, &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(10)}}})
+
+	got, err := Insert(newFakeDriver(), "INSERT INTO user (name) VALUES (?)", "foo")
+	if err != nil || got != 9 {
+		t.Errorf("got %d, %v; want 9, nil", got, err)
+	}
+
+	pg := newFakeNamedDriver("fakepg")
+
+	got2, err := Insert(pg, "INSERT INTO user (name) VALUES ($1); -- new user", "foo")
+	if err != nil || got2 != 10 {
+		t.Errorf("got %d, %v; want 10, nil", got2, err)
+	}
+
+	setFakeResult(`INSERT INTO user (name) VALUES ($1) ON CONFLICT DO NOTHING RETURNING "id"`, &fakeResult{columns: []string{"id"}})
+
+	got3, err := Insert(pg, "INSERT INTO user (name) VALUES ($1) ON CONFLICT DO NOTHING", "foo")
+	if !IsEmptyResult(err) || got3 != 0 {
+		t.Errorf("got %d, %v; want 0, empty result", got3, err)
+	}
+
+	setFakeResult(`INSERT INTO user (name) VALUES ($1), ($2) RETURNING "id"`, &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(11)}, {int64(12)}}})
+
+	got4, err := InsertContext(WithExpectedRows(context.Background(), 2), pg, "INSERT INTO user (name) VALUES ($1), ($2)", "foo", "bar")
+	if err != nil || got4 != 11 {
+		t.Errorf("got %d, %v; want 11, nil", got4, err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	setFakeResult("UPDATE user SET name = ?, version = version + 1 WHERE id = ? AND version = ?", &fakeResult{affected: 0})
+
+	d := newFakeDriver()
+
+	got, err := Update(d, "UPDATE user SET name = ?, version = version + 1 WHERE id = ? AND version = ?", "foo", 1, 3)
+	if err != nil || got != 0 {
+		t.Errorf("got %d, %v; want 0, nil", got, err)
+	}
+
+	ctx := WithExpectedRows(context.Background(), 1)
+	got2, err := UpdateContext(ctx, d, "UPDATE user SET name = ?, version = version + 1 WHERE id = ? AND version = ?", "foo", 1, 3)
+	if !errors.Is(err, ErrUnexpectedRows) || got2 != 0 {
+		t.Errorf("got %d, %v; want 0, %v", got2, err, ErrUnexpectedRows)
+	}
+}
@@ -0,0 +1,263 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type emptyInKey struct{}
+
+// 设置空切片参数的处理方式，如：FindContext(WithEmptyIn(ctx, EmptyInNull), driver, "... WHERE id IN (?)", ids)
+func WithEmptyIn(ctx context.Context, policy int) context.Context {
+	return context.WithValue(ctx, emptyInKey{}, policy)
+}
+
+// 缺省：EmptyInError
+func GetEmptyIn(ctx context.Context) int {
+	if ctx == nil {
+		return EmptyInError
+	}
+
+	if n, ok := ctx.Value(emptyInKey{}).(int); ok {
+		return n
+	}
+
+	return EmptyInError
+}
+
+// IN的参数，展开为同等数量的占位符，如：Find(driver, "... WHERE id = ANY(?) OR id IN (?)", a, In(ids))
+// IN (...)内的切片参数自动展开，其余位置的切片（如：PostgreSQL的= ANY($1)数组参数）需经In包装才展开
+type InArg struct {
+	values reflect.Value
+}
+
+// values需为切片
+func In(values interface{}) InArg {
+	return InArg{values: reflect.ValueOf(values)}
+}
+
+// 将IN (...)内的切片参数和In参数展开为同等数量的占位符，如："id IN (?)", []int64{1, 2} => "id IN (?, ?)", 1, 2
+// 支持?和$n，$n按展开后的参数重新编号，[]byte和实现driver.Valuer的切片不展开，name为驱动名，按其方言切分sql
+func ExpandIn(name string, query string, args []interface{}, emptyIn int) (string, []interface{}, error) {
+	return expandIn(GetDialect(name), query, args, emptyIn)
+}
+
+func expandIn(d Dialect, query string, args []interface{}, emptyIn int) (string, []interface{}, error) {
+	if !hasSliceArg(args) {
+		return query, args, nil
+	}
+
+	tokens := lexSql(d, query)
+	slices, err := expandArgs(tokens, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// 第i个参数展开后的起始编号，从1开始
+	starts := make([]int, len(args))
+	var r []interface{}
+	for i, arg := range args {
+		starts[i] = len(r) + 1
+
+		v, ok := slices[i]
+		if !ok {
+			r = append(r, arg)
+			continue
+		}
+
+		if v.Len() == 0 && emptyIn != EmptyInNull {
+			return "", nil, fmt.Errorf("arg %d is an empty slice", i)
+		}
+
+		for j := 0; j < v.Len(); j++ {
+			r = append(r, v.Index(j).Interface())
+		}
+	}
+
+	var b strings.Builder
+	var next int
+	for _, t := range tokens {
+		if t.kind != tokenPlaceholder {
+			b.WriteString(t.text)
+			continue
+		}
+
+		i, dollar := placeholderArg(t, &next)
+		if i < 0 || i >= len(args) {
+			return "", nil, fmt.Errorf("placeholder %s has no arg", t.text)
+		}
+
+		n := 1
+		if v, ok := slices[i]; ok {
+			n = v.Len()
+		}
+
+		if n == 0 {
+			b.WriteString("NULL")
+			continue
+		}
+
+		for j := 0; j < n; j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+
+			if dollar {
+				b.WriteString("$" + strconv.Itoa(starts[i]+j))
+			} else {
+				b.WriteString("?")
+			}
+		}
+	}
+
+	return b.String(), r, nil
+}
+
+// 占位符对应的参数下标，?按出现顺序，$n为n-1
+func placeholderArg(t sqlToken, next *int) (int, bool) {
+	if strings.HasPrefix(t.text, "$") {
+		i, _ := strconv.Atoi(t.text[1:])
+		return i - 1, true
+	}
+
+	*next++
+	return *next - 1, false
+}
+
+// 需展开的参数：In参数，及直接位于IN (...)内的切片参数
+func expandArgs(tokens []sqlToken, args []interface{}) (map[int]reflect.Value, error) {
+	r := make(map[int]reflect.Value)
+	for i, arg := range args {
+		if in, ok := arg.(InArg); ok {
+			if in.values.Kind() != reflect.Slice {
+				return nil, fmt.Errorf("arg %d: In requires a slice", i)
+			}
+
+			r[i] = in.values
+		}
+	}
+
+	// 各层括号是否是IN的列表
+	var parens []bool
+	var next int
+	var afterIn bool
+	for _, t := range tokens {
+		switch {
+		case t.kind == tokenSpace || t.kind == tokenComment:
+			continue
+		case t.kind == tokenPlaceholder:
+			i, _ := placeholderArg(t, &next)
+			if len(parens) > 0 && parens[len(parens)-1] && i >= 0 && i < len(args) {
+				if v, ok := plainSlice(args[i]); ok {
+					r[i] = v
+				}
+			}
+		case t.text == "(":
+			parens = append(parens, afterIn)
+		case t.text == ")" && len(parens) > 0:
+			parens = parens[:len(parens)-1]
+		}
+
+		afterIn = t.kind == tokenWord && strings.EqualFold(t.text, "IN")
+	}
+
+	return r, nil
+}
+
+// 可展开的切片，[]byte和实现driver.Valuer的不展开
+func plainSlice(arg interface{}) (reflect.Value, bool) {
+	if arg == nil {
+		return reflect.Value{}, false
+	}
+
+	if _, ok := arg.(driver.Valuer); ok {
+		return reflect.Value{}, false
+	}
+
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return reflect.Value{}, false
+	}
+
+	return v, true
+}
+
+// 展开后的参数数
+func countArgs(d Dialect, query string, args []interface{}) int {
+	if !hasSliceArg(args) {
+		return len(args)
+	}
+
+	slices, err := expandArgs(lexSql(d, query), args)
+	if err != nil {
+		return len(args)
+	}
+
+	n := len(args)
+	for _, v := range slices {
+		n += v.Len() - 1
+	}
+
+	return n
+}
+
+// 展开后超出limit时，按最长的展开参数拆分，其余参数不变
+func splitIn(d Dialect, query string, args []interface{}, limit int) ([][]interface{}, error) {
+	slices, err := expandArgs(lexSql(d, query), args)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(args)
+	longest, size := -1, 0
+	for i, v := range slices {
+		total += v.Len() - 1
+		if v.Len() > size || (v.Len() == size && i < longest) {
+			longest, size = i, v.Len()
+		}
+	}
+
+	if longest < 0 {
+		return nil, newKindError(ErrTooManyPlaceholders, "%d placeholders can't be greater than %d", len(args), limit)
+	}
+
+	chunk := limit - (total - size)
+	if chunk <= 0 {
+		return nil, newKindError(ErrTooManyPlaceholders, "%d placeholders can't be greater than %d", total, limit)
+	}
+
+	v := slices[longest]
+
+	var r [][]interface{}
+	for start := 0; start < size; start += chunk {
+		end := start + chunk
+		if end > size {
+			end = size
+		}
+
+		chunkArgs := append([]interface{}{}, args...)
+		chunkArgs[longest] = InArg{values: v.Slice(start, end)}
+		r = append(r, chunkArgs)
+	}
+
+	return r, nil
+}
+
+// 含In参数或切片参数时才需切分sql
+func hasSliceArg(args []interface{}) bool {
+	for _, arg := range args {
+		if _, ok := arg.(InArg); ok {
+			return true
+		}
+
+		if _, ok := plainSlice(arg); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExpandIn(t *testing.T) {
+	got, args, err := ExpandIn("mysql", "SELECT * FROM user WHERE status = ? AND id IN (?) AND name <> '?'", []interface{}{1, In([]int64{7, 8, 9})}, EmptyInError)
+	want := "SELECT * FROM user WHERE status = ? AND id IN (?, ?, ?) AND name <> '?'"
+	if err != nil || got != want || fmt.Sprint(args) != "[1 7 8 9]" {
+		t.Errorf("got %q, %v, %v; want %q, [1 7 8 9]", got, args, err, want)
+	}
+
+	got2, args2, err := ExpandIn("postgres", "SELECT * FROM user WHERE id IN ($1) AND status = $2 AND data = $3", []interface{}{In([]string{"a", "b"}), 1, []byte("x")}, EmptyInError)
+	want2 := "SELECT * FROM user WHERE id IN ($1, $2) AND status = $3 AND data = $4"
+	if err != nil || got2 != want2 || len(args2) != 4 {
+		t.Errorf("got %q, %v, %v; want %q", got2, args2, err, want2)
+	}
+
+	if _, _, err := ExpandIn("mysql", "SELECT * FROM user WHERE id IN (?)", []interface{}{In([]int{})}, EmptyInError); err == nil {
+		t.Errorf("got nil; want error")
+	}
+
+	got3, args3, err := ExpandIn("mysql", "SELECT * FROM user WHERE id IN (?)", []interface{}{In([]int{})}, EmptyInNull)
+	want3 := "SELECT * FROM user WHERE id IN (NULL)"
+	if err != nil || got3 != want3 || len(args3) != 0 {
+		t.Errorf("got %q, %v, %v; want %q", got3, args3, err, want3)
+	}
+
+	query4 := "SELECT * FROM user WHERE id = ANY($1)"
+	got4, args4, err := ExpandIn("postgres", query4, []interface{}{[]int64{7, 8}}, EmptyInError)
+	if err != nil || got4 != query4 || len(args4) != 1 {
+		t.Errorf("got %q, %v, %v; want %q, 1 arg", got4, args4, err, query4)
+	}
+
+	query5 := "SELECT * FROM user WHERE id IN (?) AND tags = ? AND uid IN (SELECT uid FROM vip WHERE level IN (?))"
+	got5, args5, err := ExpandIn("mysql", query5, []interface{}{[]int64{1, 2}, []string{"a"}, []int{3, 4}}, EmptyInError)
+	want5 := "SELECT * FROM user WHERE id IN (?, ?) AND tags = ? AND uid IN (SELECT uid FROM vip WHERE level IN (?, ?))"
+	if err != nil || got5 != want5 || fmt.Sprint(args5) != "[1 2 [a] 3 4]" {
+		t.Errorf("got %q, %v, %v; want %q, [1 2 [a] 3 4]", got5, args5, err, want5)
+	}
+
+	got6, args6, err := ExpandIn("postgres", "SELECT * FROM user WHERE id IN ($1) AND data = $2", []interface{}{[]int64{1, 2}, []byte("x")}, EmptyInError)
+	want6 := "SELECT * FROM user WHERE id IN ($1, $2) AND data = $3"
+	if err != nil || got6 != want6 || len(args6) != 3 {
+		t.Errorf("got %q, %v, %v; want %q, 3 args", got6, args6, err, want6)
+	}
+
+	if _, _, err := ExpandIn("mysql", "SELECT * FROM user WHERE id IN (?)", []interface{}{In(1)}, EmptyInError); err == nil {
+		t.Errorf("got nil; want error")
+	}
+}
+
+func TestFindChunks(t *testing.T) {
+	in := func(n int) string {
+		return "SELECT id FROM user WHERE id IN (" + strings.Repeat("?, ", n-1) + "?)"
+	}
+
+	setFakeResult(in(65535), &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}})
+	setFakeResult(in(2), &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(65537)}}})
+
+	d := newFakeDriver()
+	ids := make([]int64, 65537)
+
+	got, err := FindOK(d, "SELECT id FROM user WHERE id IN (?)", ids)
+	if err != nil || len(got) != 2 {
+		t.Errorf("got %v, %v; want 2 rows, nil", got, err)
+	}
+
+	_, err = QueryContext(WithEmptyIn(context.Background(), EmptyInError), d, "SELECT id FROM user WHERE id IN (?)", In([]int64{}))
+	if err == nil {
+		t.Errorf("got nil; want error")
+	}
+
+	_, err = Exec(d, "DELETE FROM user WHERE id IN (?)", In(ids))
+	if !errors.Is(err, ErrTooManyPlaceholders) {
+		t.Errorf("got %v; want %v", err, ErrTooManyPlaceholders)
+	}
+
+	query := "SELECT id FROM user WHERE id IN (?)"
+	_, err, _ = First(d, query, In(ids))
+	if !errors.Is(err, ErrTooManyPlaceholders) {
+		t.Errorf("First got %v; want %v", err, ErrTooManyPlaceholders)
+	}
+
+	_, err = One(d, query, In(ids))
+	if !errors.Is(err, ErrTooManyPlaceholders) {
+		t.Errorf("One got %v; want %v", err, ErrTooManyPlaceholders)
+	}
+
+	_, err, _ = Aggregate(d, query, In(ids))
+	if !errors.Is(err, ErrTooManyPlaceholders) {
+		t.Errorf("Aggregate got %v; want %v", err, ErrTooManyPlaceholders)
+	}
+
+	err = Each(context.Background(), d, query, []interface{}{In(ids)}, func(row map[string]interface{}) error {
+		return nil
+	})
+	if !errors.Is(err, ErrTooManyPlaceholders) {
+		t.Errorf("Each got %v; want %v", err, ErrTooManyPlaceholders)
+	}
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// 测试用驱动，按query返回预设的结果
+type fakeResult struct {
+	columns  []string
+	types    []string // 列类型，DatabaseTypeName
+	lengths  []int64  // 列长度，0-不支持
+	rows     [][]driver.Value
+	affected int64
+	insertId int64
+	err      error
+	fails    int   // 前fails次调用返回err，之后正常返回
+	iterErr  error // 遍历完rows后返回的错误，缺省：io.EOF
+}
+
+var fakeResults = struct {
+	mu   sync.Mutex
+	data map[string]*fakeResult
+}{data: make(map[string]*fakeResult)}
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+	_ = RegisterDialect("fake", MySQLDialect{})
+	sql.Register("fakepg", fakeDriver{})
+	_ = RegisterDialect("fakepg", PostgresDialect{})
+	sql.Register("fakesqlite", fakeDriver{})
+	_ = RegisterDialect("fakesqlite", SqliteDialect{})
+}
+
+func setFakeResult(query string, r *fakeResult) {
+	fakeResults.mu.Lock()
+	defer fakeResults.mu.Unlock()
+
+	fakeResults.data[query] = r
+}
+
+func getFakeResult(query string) (*fakeResult, error) {
+	fakeResults.mu.Lock()
+	defer fakeResults.mu.Unlock()
+
+	r, ok := fakeResults.data[query]
+	if !ok {
+		return nil, errors.New("fake: unexpected query " + query)
+	}
+
+	if r.fails > 0 {
+		r.fails--
+		return nil, r.err
+	}
+
+	if r.fails < 0 {
+		return r, r.err
+	}
+
+	return r, nil
+}
+
+func newFakeDriver() *Driver {
+	return newFakeNamedDriver("fake")
+}
+
+// fakepg按PostgreSQL方言
+func newFakeNamedDriver(name string) *Driver {
+	b := &DriverBuilder{}
+	d, err := b.SetName(name).SetSchema(&Schema{host: "127.0.0.1", port: Port, database: "test", username: "root"}).Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return fakeConn{dsn: name}, nil
+}
+
+// 库名为unreachable时，Ping返回错误
+type fakeConn struct {
+	dsn string
+}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{query: query}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (x fakeConn) Ping(ctx context.Context) error {
+	if strings.Contains(x.dsn, "/unreachable") {
+		return errors.New("fake: connection refused")
+	}
+
+	return nil
+}
+
+// 回滚次数
+var fakeRollbacks atomic.Int64
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	fakeRollbacks.Add(1)
+	return nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (x fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	r, err := getFakeResult(x.query)
+	if err != nil {
+		return nil, err
+	}
+
+	return fakeExecResult{r}, nil
+}
+
+func (x fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	r, err := getFakeResult(x.query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeRows{result: r}, nil
+}
+
+type fakeExecResult struct {
+	result *fakeResult
+}
+
+func (x fakeExecResult) LastInsertId() (int64, error) {
+	return x.result.insertId, nil
+}
+
+func (x fakeExecResult) RowsAffected() (int64, error) {
+	return x.result.affected, nil
+}
+
+type fakeRows struct {
+	result *fakeResult
+	pos    int
+}
+
+func (x *fakeRows) Columns() []string {
+	return x.result.columns
+}
+
+func (x *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	if index < len(x.result.types) {
+		return x.result.types[index]
+	}
+
+	return ""
+}
+
+func (x *fakeRows) ColumnTypeLength(index int) (int64, bool) {
+	if index < len(x.result.lengths) && x.result.lengths[index] > 0 {
+		return x.result.lengths[index], true
+	}
+
+	return 0, false
+}
+
+func (x *fakeRows) Close() error {
+	return nil
+}
+
+func (x *fakeRows) Next(dest []driver.Value) error {
+	if x.pos >= len(x.result.rows) {
+		if x.result.iterErr != nil {
+			return x.result.iterErr
+		}
+
+		return io.EOF
+	}
+
+	copy(dest, x.result.rows[x.pos])
+	x.pos++
+	return nil
+}
@@ -1,27 +1,62 @@
 package database
 
-import "errors"
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
 
 func Find(driver *Driver, query string, args ...interface{}) (result []map[string]interface{}, err error, closeErr error) {
-	if driver == nil {
-		err = errors.New("driver can't be nil")
-		return
+	return FindContext(context.Background(), driver, query, args...)
+}
+
+// 切片参数展开后超出占位符上限时，按最长的切片拆分为多次查询，合并结果，ORDER BY和LIMIT仅在每次查询内生效
+func FindContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (result []map[string]interface{}, err error, closeErr error) {
+	if driver != nil && countArgs(driver.GetDialect(), query, args) > driver.GetDialect().GetMaxPlaceholders() {
+		return findChunks(ctx, driver, query, args)
 	}
 
-	db := driver.GetDb()
-	if db == nil {
-		err = errors.New("db can't be nil")
-		return
+	err = run(ctx, driver, MethodFind, query, args, func(db *sql.DB, c *Call) error {
+		result, closeErr = nil, nil
+
+		rows, err := db.QueryContext(c.ctx, c.query, c.args...)
+		if err == nil {
+			result, err = driver.scanner.Scan(rows)
+			c.rows = int64(len(result))
+		}
+
+		if rows != nil {
+			closeErr = rows.Close()
+		}
+
+		return err
+	})
+
+	return
+}
+
+func findChunks(ctx context.Context, driver *Driver, query string, args []interface{}) (result []map[string]interface{}, err error, closeErr error) {
+	chunks, err := splitIn(driver.GetDialect(), query, args, driver.GetDialect().GetMaxPlaceholders())
+	if err != nil {
+		return nil, wrapQueryError(driver, query, err), nil
 	}
 
-	rows, err := db.Query(query, args...)
-	if err == nil {
-		result, err = Scan(rows)
+	for _, chunkArgs := range chunks {
+		r, err2, closeErr2 := FindContext(ctx, driver, query, chunkArgs...)
+		if closeErr2 != nil {
+			closeErr = errors.Join(closeErr, closeErr2)
+		}
+
+		if err2 != nil && !IsEmptyResult(err2) {
+			return nil, err2, closeErr
+		}
+
+		result = append(result, r...)
 	}
 
-	if rows != nil {
-		closeErr = rows.Close()
+	if len(result) == 0 {
+		return nil, NewEmptyResult(), closeErr
 	}
 
-	return
+	return result, nil, closeErr
 }
@@ -1,7 +1,10 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 )
 
@@ -12,22 +15,7 @@ func AggregateInt(driver *Driver, query string, args ...interface{}) (result int
 		return
 	}
 
-	if r == nil {
-		return
-	}
-
-	switch r.(type) {
-	case int64:
-		result = r.(int64)
-		break
-	case []uint8:
-		result, err = strconv.ParseInt(string(r.([]uint8)), 10, 64)
-		break
-	default:
-		err = fmt.Errorf("unsupported type %v", r)
-		break
-	}
-
+	result, err = aggregateInt(r)
 	return
 }
 
@@ -38,28 +26,23 @@ func AggregateFloat(driver *Driver, query string, args ...interface{}) (result f
 		return
 	}
 
-	if r == nil {
-		return
-	}
-
-	switch r.(type) {
-	case float64:
-		result = r.(float64)
-		break
-	case []uint8:
-		result, err = strconv.ParseFloat(string(r.([]uint8)), 64)
-		break
-	default:
-		err = fmt.Errorf("unsupported type %v", r)
-		break
-	}
-
+	result, err = aggregateFloat(r)
 	return
 }
 
 // "AS 'aggregate'" must be contained in Query
+// 无结果时（如空表GROUP BY）与NULL一致，返回nil，不返回"empty result"
+// 不拆分切片参数，超出占位符上限时返回ErrTooManyPlaceholders
 func Aggregate(driver *Driver, query string, args ...interface{}) (result interface{}, err error, closeErr error) {
-	r, err, closeErr := First(driver, query, args...)
+	return aggregateContext(context.Background(), driver, query, args)
+}
+
+func aggregateContext(ctx context.Context, driver *Driver, query string, args []interface{}) (result interface{}, err error, closeErr error) {
+	r, err, closeErr := first(ctx, MethodAggregate, driver, query, args)
+	if IsEmptyResult(err) {
+		return nil, nil, closeErr
+	}
+
 	if err != nil {
 		return
 	}
@@ -72,3 +55,86 @@ func Aggregate(driver *Driver, query string, args ...interface{}) (result interf
 
 	return
 }
+
+// NULL视为0，兼容Scanner的类型转换：uint64、DECIMAL的string和*big.Rat，须为整数且不超出int64
+func aggregateInt(r interface{}) (int64, error) {
+	switch v := r.(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return v, nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("%d overflows int64", v)
+		}
+
+		return int64(v), nil
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, fmt.Errorf("%v isn't an int64", v)
+		}
+
+		return int64(v), nil
+	case []uint8:
+		return parseAggregateInt(string(v))
+	case string:
+		return parseAggregateInt(v)
+	case *big.Rat:
+		return ratInt(v)
+	default:
+		return 0, fmt.Errorf("unsupported type %v", r)
+	}
+}
+
+// 整数或小数部分为0的DECIMAL，如：SUM(DECIMAL(10,2))的"15.00"
+func parseAggregateInt(s string) (int64, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("%q isn't a number", s)
+	}
+
+	return ratInt(r)
+}
+
+func ratInt(r *big.Rat) (int64, error) {
+	if r == nil {
+		return 0, nil
+	}
+
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("%s isn't an int64", r.RatString())
+	}
+
+	return r.Num().Int64(), nil
+}
+
+// NULL视为0，兼容Scanner的类型转换：int64、uint64、DECIMAL的string和*big.Rat
+func aggregateFloat(r interface{}) (float64, error) {
+	switch v := r.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case []uint8:
+		return strconv.ParseFloat(string(v), 64)
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case *big.Rat:
+		if v == nil {
+			return 0, nil
+		}
+
+		f, _ := v.Float64()
+		return f, nil
+	default:
+		return 0, fmt.Errorf("unsupported type %v", r)
+	}
+}
@@ -1,27 +1,39 @@
 package database
 
-import "errors"
+import (
+	"context"
+	"database/sql"
+)
 
 func First(driver *Driver, query string, args ...interface{}) (result map[string]interface{}, err error, closeErr error) {
-	if driver == nil {
-		err = errors.New("driver can't be nil")
-		return
-	}
-
-	db := driver.GetDb()
-	if db == nil {
-		err = errors.New("db can't be nil")
-		return
-	}
-
-	rows, err := db.Query(query, args...)
-	if err == nil {
-		result, err = ScanFirst(rows)
-	}
-
-	if rows != nil {
-		closeErr = rows.Close()
-	}
+	return FirstContext(context.Background(), driver, query, args...)
+}
+
+// 仅FindContext拆分切片参数，超出占位符上限时返回ErrTooManyPlaceholders
+func FirstContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (result map[string]interface{}, err error, closeErr error) {
+	return first(ctx, MethodFirst, driver, query, args)
+}
+
+func first(ctx context.Context, method string, driver *Driver, query string, args []interface{}) (result map[string]interface{}, err error, closeErr error) {
+	err = run(ctx, driver, method, query, args, func(db *sql.DB, c *Call) error {
+		result, closeErr = nil, nil
+
+		rows, err := db.QueryContext(c.ctx, c.query, c.args...)
+		if err == nil {
+			result, err = driver.scanner.ScanFirst(rows)
+			if result != nil {
+				c.rows = 1
+			} else if IsEmptyResult(err) {
+				c.rows = 0
+			}
+		}
+
+		if rows != nil {
+			closeErr = rows.Close()
+		}
+
+		return err
+	})
 
 	return
 }
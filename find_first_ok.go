@@ -0,0 +1,21 @@
+package database
+
+import "context"
+
+// 查询首行，无结果时found为false，不返回"empty result"，关闭rows的错误并入err
+func FirstOK(driver *Driver, query string, args ...interface{}) (result map[string]interface{}, found bool, err error) {
+	return FirstOKContext(context.Background(), driver, query, args...)
+}
+
+func FirstOKContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (result map[string]interface{}, found bool, err error) {
+	result, err, closeErr := first(ctx, MethodFirst, driver, query, args)
+	if IsEmptyResult(err) {
+		return nil, false, closeErr
+	}
+
+	if err = joinClose(err, closeErr); err != nil {
+		return nil, false, err
+	}
+
+	return result, true, nil
+}
@@ -0,0 +1,17 @@
+package database
+
+import "context"
+
+// 查询多行，无结果时返回空切片，不返回"empty result"，关闭rows的错误并入err
+func FindOK(driver *Driver, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return FindOKContext(context.Background(), driver, query, args...)
+}
+
+func FindOKContext(ctx context.Context, driver *Driver, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	result, err, closeErr := FindContext(ctx, driver, query, args...)
+	if IsEmptyResult(err) {
+		return []map[string]interface{}{}, closeErr
+	}
+
+	return result, joinClose(err, closeErr)
+}
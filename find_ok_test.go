@@ -0,0 +1,34 @@
+package database
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestFindOK(t *testing.T) {
+	setFakeResult("SELECT id FROM user WHERE id = 0", &fakeResult{columns: []string{"id"}})
+	setFakeResult("SELECT id FROM user WHERE id = 1", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}})
+	setFakeResult("SELECT SUM(id) AS 'aggregate' FROM user GROUP BY name", &fakeResult{columns: []string{AggregateAlias}})
+
+	d := newFakeDriver()
+
+	got, err := FindOK(d, "SELECT id FROM user WHERE id = 0")
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("got %v, %v; want [], nil", got, err)
+	}
+
+	got2, found, err := FirstOK(d, "SELECT id FROM user WHERE id = 0")
+	if err != nil || found || got2 != nil {
+		t.Errorf("got %v, %v, %v; want nil, false, nil", got2, found, err)
+	}
+
+	got3, found3, err := FirstOK(d, "SELECT id FROM user WHERE id = 1")
+	if err != nil || !found3 || got3["id"] != int64(1) {
+		t.Errorf("got %v, %v, %v; want map[id:1], true, nil", got3, found3, err)
+	}
+
+	got4, err, _ := AggregateInt(d, "SELECT SUM(id) AS 'aggregate' FROM user GROUP BY name")
+	if err != nil || got4 != 0 {
+		t.Errorf("got %v, %v; want 0, nil", got4, err)
+	}
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// 按唯一键查询一行，多于一行时返回ErrMultipleRows，错误信息含sql指纹，关闭rows的错误并入返回的错误
+func One(driver *Driver, query string, args ...interface{}) (map[string]interface{}, error) {
+	return OneContext(context.Background(), driver, query, args...)
+}
+
+// 不拆分切片参数，超出占位符上限时返回ErrTooManyPlaceholders
+func OneContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	var closeErr error
+
+	err := run(ctx, driver, MethodOne, query, args, func(db *sql.DB, c *Call) error {
+		result, closeErr = nil, nil
+
+		rows, err := db.QueryContext(c.ctx, c.query, c.args...)
+		if err == nil {
+			result, err = driver.scanner.ScanOne(rows)
+			if result != nil {
+				c.rows = 1
+			} else if IsEmptyResult(err) {
+				c.rows = 0
+			} else if errors.Is(err, ErrMultipleRows) {
+				err = newKindError(ErrMultipleRows, `multiple rows, fingerprint "%s"`, fingerprintSql(driver.GetDialect(), c.query))
+			}
+		}
+
+		if rows != nil {
+			closeErr = rows.Close()
+		}
+
+		return err
+	})
+
+	if err = joinClose(err, closeErr); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOne(t *testing.T) {
+	setFakeResult("SELECT * FROM user WHERE email = 'a@b.c'", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}})
+	setFakeResult("SELECT * FROM user WHERE email = 'x@y.z'", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}})
+
+	d := newFakeDriver()
+
+	got, err := One(d, "SELECT * FROM user WHERE email = 'a@b.c'")
+	if err != nil || got["id"] != int64(1) {
+		t.Errorf("got %v, %v; want map[id:1], nil", got, err)
+	}
+
+	got2, err := One(d, "SELECT * FROM user WHERE email = 'x@y.z'")
+	want := `fingerprint "select * from user where email = ?"`
+	if !errors.Is(err, ErrMultipleRows) || !strings.Contains(err.Error(), want) || got2 != nil {
+		t.Errorf("got %v, %v; want nil, error containing %q", got2, err, want)
+	}
+}
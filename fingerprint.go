@@ -0,0 +1,56 @@
+package database
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	fingerprintComma  = regexp.MustCompile(`\s*,\s*`)
+	fingerprintOpen   = regexp.MustCompile(`\(\s+`)
+	fingerprintClose  = regexp.MustCompile(`\s+\)`)
+	fingerprintIn     = regexp.MustCompile(`\bin \((?:\?, )*\?\)`)
+	fingerprintValues = regexp.MustCompile(`\bvalues \((?:\?, )*\?\)(?:, \((?:\?, )*\?\))*`)
+)
+
+// sql指纹，去掉字面量和注释，合并空白，小写，IN列表和多行VALUES折叠为(?+)，按MySQL语法切分
+// 如：SELECT * FROM user WHERE id IN (1, 2, 3) => select * from user where id in (?+)
+func Fingerprint(query string) string {
+	return fingerprintSql(nil, query)
+}
+
+// 按方言切分sql取指纹，d为nil时按MySQL
+func fingerprintSql(d Dialect, query string) string {
+	var bucket strings.Builder
+
+	space := false
+	for _, t := range lexSql(d, query) {
+		switch t.kind {
+		case tokenSpace, tokenComment:
+			space = bucket.Len() > 0
+			continue
+		}
+
+		if space {
+			bucket.WriteRune(' ')
+			space = false
+		}
+
+		switch t.kind {
+		case tokenString, tokenNumber, tokenPlaceholder:
+			bucket.WriteRune('?')
+		case tokenQuoted:
+			bucket.WriteString(t.text)
+		default:
+			bucket.WriteString(strings.ToLower(t.text))
+		}
+	}
+
+	r := bucket.String()
+	r = fingerprintComma.ReplaceAllString(r, ", ")
+	r = fingerprintOpen.ReplaceAllString(r, "(")
+	r = fingerprintClose.ReplaceAllString(r, ")")
+	r = fingerprintIn.ReplaceAllString(r, "in (?+)")
+	r = fingerprintValues.ReplaceAllString(r, "values (?+)")
+	return strings.TrimSuffix(r, ";")
+}
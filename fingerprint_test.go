@@ -0,0 +1,23 @@
+package database
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	got := Fingerprint("SELECT  *\n FROM user WHERE id IN (1,2, 3) AND name = 'it''s' -- comment")
+	want := "select * from user where id in (?+) and name = ?"
+	if got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	got2 := Fingerprint("INSERT INTO user (name, phone) VALUES (?, ?), ('a', 'b');")
+	want2 := "insert into user (name, phone) values (?+)"
+	if got2 != want2 {
+		t.Errorf("got %q; want %q", got2, want2)
+	}
+
+	got3 := Fingerprint("select * from user where id in ( $1 , $2 )")
+	want3 := "select * from user where id in (?+)"
+	if got3 != want3 {
+		t.Errorf("got %q; want %q", got3, want3)
+	}
+}
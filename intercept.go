@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 一次Exec、Find、First、Aggregate调用，经拦截器链传递
+type Call struct {
+	ctx      context.Context
+	driver   *Driver
+	method   string        // 调用方法，MethodExec、MethodFind、...
+	query    string        // sql
+	args     []interface{} // 参数
+	start    time.Time     // 开始时间
+	duration time.Duration // 耗时，不含拦截器
+	rows     int64         // Exec：影响行数，Find、First、Aggregate：返回行数，缺省：-1-未知
+	err      error
+}
+
+func (x *Call) GetContext() context.Context {
+	return x.ctx
+}
+
+func (x *Call) GetDriver() *Driver {
+	return x.driver
+}
+
+func (x *Call) GetMethod() string {
+	return x.method
+}
+
+func (x *Call) GetQuery() string {
+	return x.query
+}
+
+func (x *Call) GetArgs() []interface{} {
+	return x.args
+}
+
+func (x *Call) GetStart() time.Time {
+	return x.start
+}
+
+func (x *Call) GetDuration() time.Duration {
+	return x.duration
+}
+
+func (x *Call) GetRows() int64 {
+	return x.rows
+}
+
+func (x *Call) GetErr() error {
+	return x.err
+}
+
+// 调用next前设置，替换传给下游的ctx，如：携带Span
+func (x *Call) SetContext(ctx context.Context) {
+	if ctx != nil {
+		x.ctx = ctx
+	}
+}
+
+// 调用next前设置，替换执行的sql
+func (x *Call) SetQuery(s string) {
+	x.query = s
+}
+
+// 调用next前设置，替换执行的参数
+func (x *Call) SetArgs(args []interface{}) {
+	x.args = args
+}
+
+type Handler func(c *Call) error
+
+// 拦截器，包裹每次调用，如：日志、链路追踪、指标、重试、保护
+// 调用next执行下游，next返回后，可读取耗时、行数和错误
+type Interceptor interface {
+	Intercept(c *Call, next Handler) error
+}
+
+type InterceptorFunc func(c *Call, next Handler) error
+
+func (f InterceptorFunc) Intercept(c *Call, next Handler) error {
+	return f(c, next)
+}
+
+var globalInterceptors struct {
+	mu   sync.RWMutex // ensures atomic writes; protects the following list
+	list []Interceptor
+}
+
+// 注册全局拦截器，先于Driver的拦截器执行
+func AddInterceptor(i Interceptor) error {
+	if i == nil {
+		return errors.New("interceptor can't be nil")
+	}
+
+	globalInterceptors.mu.Lock()
+	defer globalInterceptors.mu.Unlock()
+
+	globalInterceptors.list = append(globalInterceptors.list, i)
+	return nil
+}
+
+// 注册Driver的拦截器，后于全局拦截器执行
+func (x *Driver) AddInterceptor(i Interceptor) error {
+	if i == nil {
+		return errors.New("interceptor can't be nil")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.interceptors = append(x.interceptors, i)
+	return nil
+}
+
+func (x *Driver) GetInterceptors() []Interceptor {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.interceptors
+}
+
+// 按全局拦截器、Driver的拦截器顺序包裹fn，执行一次调用
+func invoke(c *Call, fn Handler) error {
+	globalInterceptors.mu.RLock()
+	chain := append([]Interceptor{}, globalInterceptors.list...)
+	globalInterceptors.mu.RUnlock()
+
+	chain = append(chain, c.driver.GetInterceptors()...)
+
+	h := func(c *Call) error {
+		c.start = time.Now()
+		c.err = fn(c)
+		c.duration = time.Since(c.start)
+		return c.err
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		interceptor, next := chain[i], h
+		h = func(c *Call) error {
+			return interceptor.Intercept(c, next)
+		}
+	}
+
+	return h(c)
+}
+
+func newCall(ctx context.Context, driver *Driver, method string, query string, args []interface{}) *Call {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &Call{
+		ctx:    ctx,
+		driver: driver,
+		method: method,
+		query:  query,
+		args:   args,
+		rows:   -1,
+	}
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestInterceptor(t *testing.T) {
+	setFakeResult("SELECT id FROM user", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}})
+
+	d := newFakeDriver()
+
+	var got []string
+	var rows int64
+	_ = d.AddInterceptor(InterceptorFunc(func(c *Call, next Handler) error {
+		got = append(got, "before "+c.GetMethod())
+		err := next(c)
+		rows = c.GetRows()
+		got = append(got, "after "+c.GetMethod())
+		return err
+	}))
+
+	_, err, _ := Find(d, "SELECT id FROM user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"before Find", "after Find"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	if rows != 2 {
+		t.Errorf("got %d; want 2", rows)
+	}
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// 逐行遍历查询结果，不在内存中累积，每行复用同一扫描缓冲区
+// 拦截器和熔断器只经过执行查询，不含遍历，遍历期间连接保持使用中，关闭时等待Close
+type Iterator struct {
+	driver  *Driver
+	query   string
+	rows    *sql.Rows
+	columns *scanColumns
+	row     map[string]interface{}
+	err     error
+	closed  bool
+}
+
+func Iterate(driver *Driver, query string, args ...interface{}) (*Iterator, error) {
+	return IterateContext(context.Background(), driver, query, args...)
+}
+
+// 使用完必须调用Close，Next返回false时已自动关闭
+func IterateContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (*Iterator, error) {
+	return iterate(ctx, MethodIterate, driver, query, args)
+}
+
+func iterate(ctx context.Context, method string, driver *Driver, query string, args []interface{}) (*Iterator, error) {
+	r := &Iterator{driver: driver, query: query}
+
+	err := run(ctx, driver, method, query, args, func(db *sql.DB, c *Call) error {
+		rows, err := db.QueryContext(c.ctx, c.query, c.args...)
+		if err != nil {
+			return err
+		}
+
+		columns, err := driver.scanner.prepare(rows)
+		if err != nil {
+			_ = rows.Close()
+			return err
+		}
+
+		r.rows, r.columns = rows, columns
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// 读取下一行，无下一行或出错时返回false，并关闭rows
+func (x *Iterator) Next() bool {
+	if x.closed {
+		return false
+	}
+
+	if !x.rows.Next() {
+		x.fail(x.rows.Err())
+		return false
+	}
+
+	row, err := x.columns.scan(x.rows)
+	if err != nil {
+		x.fail(err)
+		return false
+	}
+
+	x.row = row
+	return true
+}
+
+// 当前行，每次Next返回新的map，可在遍历后继续持有
+func (x *Iterator) Row() map[string]interface{} {
+	return x.row
+}
+
+// 遍历或关闭rows的错误
+func (x *Iterator) Err() error {
+	return x.err
+}
+
+// 可重复调用
+func (x *Iterator) Close() error {
+	if !x.closed {
+		x.fail(nil)
+	}
+
+	return x.err
+}
+
+func (x *Iterator) fail(err error) {
+	x.row = nil
+	x.closed = true
+	x.err = wrapQueryError(x.driver, x.query, joinClose(err, x.rows.Close()))
+}
+
+// 逐行回调，fn返回错误时停止遍历，原样返回该错误
+// 不拆分切片参数，超出占位符上限时返回ErrTooManyPlaceholders
+func Each(ctx context.Context, driver *Driver, query string, args []interface{}, fn func(row map[string]interface{}) error) error {
+	it, err := iterate(ctx, MethodEach, driver, query, args)
+	if err != nil {
+		return err
+	}
+
+	for it.Next() {
+		if err := fn(it.Row()); err != nil {
+			return joinClose(err, it.Close())
+		}
+	}
+
+	return it.Close()
+}
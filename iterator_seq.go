@@ -0,0 +1,31 @@
+//go:build go1.23
+
+package database
+
+import (
+	"context"
+	"iter"
+)
+
+// 用于range-over-func，出错时产出(nil, err)后停止，提前break时自动关闭
+func Seq(ctx context.Context, driver *Driver, query string, args ...interface{}) iter.Seq2[map[string]interface{}, error] {
+	return func(yield func(map[string]interface{}, error) bool) {
+		it, err := IterateContext(ctx, driver, query, args...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		defer it.Close()
+
+		for it.Next() {
+			if !yield(it.Row(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
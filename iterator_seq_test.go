@@ -0,0 +1,33 @@
+//go:build go1.23
+
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	setFakeResult("SELECT id FROM backup_goods", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}})
+
+	d := newFakeDriver()
+
+	var got []interface{}
+	for row, err := range Seq(context.Background(), d, "SELECT id FROM backup_goods") {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, row["id"])
+		break
+	}
+
+	if len(got) != 1 || got[0] != int64(1) {
+		t.Errorf("got %v; want [1]", got)
+	}
+
+	if n := d.GetDb().Stats().InUse; n != 0 {
+		t.Errorf("got %d in use; want 0", n)
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestEach(t *testing.T) {
+	setFakeResult("SELECT id FROM backup_user", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}}})
+
+	d := newFakeDriver()
+
+	var got []interface{}
+	err := Each(context.Background(), d, "SELECT id FROM backup_user", nil, func(row map[string]interface{}) error {
+		got = append(got, row["id"])
+		return nil
+	})
+
+	if err != nil || len(got) != 3 {
+		t.Errorf("got %v, %v; want [1 2 3], nil", got, err)
+	}
+
+	stop := errors.New("stop")
+	got = nil
+	err = Each(context.Background(), d, "SELECT id FROM backup_user", nil, func(row map[string]interface{}) error {
+		got = append(got, row["id"])
+		if len(got) == 2 {
+			return stop
+		}
+
+		return nil
+	})
+
+	if err != stop || len(got) != 2 {
+		t.Errorf("got %v, %v; want [1 2], %v", got, err, stop)
+	}
+
+	if n := d.GetDb().Stats().InUse; n != 0 {
+		t.Errorf("got %d in use; want 0", n)
+	}
+}
+
+func TestIterator(t *testing.T) {
+	broken := errors.New("broken pipe")
+	setFakeResult("SELECT id FROM backup_order", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}, iterErr: broken})
+
+	it, err := Iterate(newFakeDriver(), "SELECT id FROM backup_order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+
+	var n int
+	for it.Next() {
+		n++
+	}
+
+	if n != 1 || !errors.Is(it.Err(), broken) {
+		t.Errorf("got %d, %v; want 1, %v", n, it.Err(), broken)
+	}
+}
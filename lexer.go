@@ -0,0 +1,169 @@
+package database
+
+import "strings"
+
+const (
+	tokenSpace       = iota // 空白
+	tokenComment            // 注释，-- ...、/* ... */，MySQL还有# ...
+	tokenString             // 字符串，'...'，PostgreSQL还有E'...'、$$...$$、$tag$...$tag$
+	tokenQuoted             // 标识符，"..."、`...`
+	tokenNumber             // 数字，1、1.5、0x1F
+	tokenWord               // 关键字或标识符
+	tokenPlaceholder        // 占位符，?、$1
+	tokenOther              // 其它符号，如：(、,、::
+)
+
+type sqlToken struct {
+	kind int
+	text string
+}
+
+// 按方言切分sql，字符串、标识符和注释内的符号不做解析，d为nil时按MySQL
+// #注释、反斜杠转义和$$字符串按方言区分，如：PostgreSQL的#>>是运算符
+func lexSql(d Dialect, query string) []sqlToken {
+	if d == nil {
+		d = MySQLDialect{}
+	}
+
+	hashComment := d.SupportsHashComment()
+	backslash := d.SupportsBackslashEscape()
+	dollarQuote := d.SupportsDollarQuote()
+
+	var r []sqlToken
+
+	n := len(query)
+	for i := 0; i < n; {
+		c := query[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for i < n && (query[i] == ' ' || query[i] == '\t' || query[i] == '\n' || query[i] == '\r') {
+				i++
+			}
+			r = append(r, sqlToken{tokenSpace, query[start:i]})
+		case c == '-' && i+1 < n && query[i+1] == '-', c == '#' && hashComment:
+			for i < n && query[i] != '\n' {
+				i++
+			}
+			r = append(r, sqlToken{tokenComment, query[start:i]})
+		case c == '/' && i+1 < n && query[i+1] == '*':
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = n
+			}
+			r = append(r, sqlToken{tokenComment, query[start:i]})
+		case c == '\'':
+			i = lexQuote(query, i, '\'', backslash)
+			r = append(r, sqlToken{tokenString, query[start:i]})
+		case c == '"' || c == '`':
+			i = lexQuote(query, i, c, backslash && c == '"')
+			r = append(r, sqlToken{tokenQuoted, query[start:i]})
+		case c == '$' && dollarQuote && dollarTag(query[i:]) != "":
+			tag := dollarTag(query[i:])
+			if end := strings.Index(query[i+len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = n
+			}
+			r = append(r, sqlToken{tokenString, query[start:i]})
+		case (c == 'E' || c == 'e') && !backslash && i+1 < n && query[i+1] == '\'':
+			i = lexQuote(query, i+1, '\'', true)
+			r = append(r, sqlToken{tokenString, query[start:i]})
+		case isDigit(c) || (c == '.' && i+1 < n && isDigit(query[i+1])):
+			if c == '0' && i+1 < n && (query[i+1] == 'x' || query[i+1] == 'X') {
+				i += 2
+				for i < n && isHex(query[i]) {
+					i++
+				}
+			} else {
+				for i < n && (isDigit(query[i]) || query[i] == '.') {
+					i++
+				}
+
+				if i < n && (query[i] == 'e' || query[i] == 'E') {
+					i++
+					if i < n && (query[i] == '+' || query[i] == '-') {
+						i++
+					}
+					for i < n && isDigit(query[i]) {
+						i++
+					}
+				}
+			}
+			r = append(r, sqlToken{tokenNumber, query[start:i]})
+		case isWord(c):
+			for i < n && (isWord(query[i]) || isDigit(query[i]) || (dollarQuote && query[i] == '$')) {
+				i++
+			}
+			r = append(r, sqlToken{tokenWord, query[start:i]})
+		case c == '?':
+			i++
+			r = append(r, sqlToken{tokenPlaceholder, query[start:i]})
+		case c == '$' && i+1 < n && isDigit(query[i+1]):
+			i++
+			for i < n && isDigit(query[i]) {
+				i++
+			}
+			r = append(r, sqlToken{tokenPlaceholder, query[start:i]})
+		case c == ':' && i+1 < n && query[i+1] == ':':
+			i += 2
+			r = append(r, sqlToken{tokenOther, query[start:i]})
+		default:
+			i++
+			r = append(r, sqlToken{tokenOther, query[start:i]})
+		}
+	}
+
+	return r
+}
+
+// 跳过引号内的内容，支持重复引号，backslash为true时支持反斜杠转义，返回结束引号后的位置
+func lexQuote(query string, i int, quote byte, backslash bool) int {
+	n := len(query)
+	for i++; i < n; i++ {
+		switch query[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			if i+1 < n && query[i+1] == quote {
+				i++
+			} else {
+				return i + 1
+			}
+		}
+	}
+
+	return n
+}
+
+// $$或$tag$开头时返回该标记，否则返回空
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+
+		if !isWord(c) && !(i > 1 && isDigit(c)) {
+			return ""
+		}
+	}
+
+	return ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isWord(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
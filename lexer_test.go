@@ -0,0 +1,40 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLexSqlDialect(t *testing.T) {
+	query := "SELECT * FROM doc WHERE data #>> '{a}' = ? AND id IN (?)"
+
+	got, args, err := ExpandIn("postgres", query, []interface{}{"x", In([]int{1, 2})}, EmptyInError)
+	want := "SELECT * FROM doc WHERE data #>> '{a}' = ? AND id IN (?, ?)"
+	if err != nil || got != want || len(args) != 3 {
+		t.Errorf("got %q, %v, %v; want %q, 3 args", got, args, err, want)
+	}
+
+	got2, _, _ := NewSelect("doc").Where("data #>> '{a}' = ?", "x").ToSql("postgres")
+	want2 := `SELECT * FROM "doc" WHERE data #>> '{a}' = $1`
+	if got2 != want2 {
+		t.Errorf("got %q; want %q", got2, want2)
+	}
+
+	got3, args3, err := BindNamed("postgres", "SELECT * FROM doc WHERE data #>> '{a}' = :v", map[string]interface{}{"v": "x"})
+	want3 := "SELECT * FROM doc WHERE data #>> '{a}' = $1"
+	if err != nil || got3 != want3 || fmt.Sprint(args3) != "[x]" {
+		t.Errorf("got %q, %v, %v; want %q, [x]", got3, args3, err, want3)
+	}
+
+	got4 := rebind(PostgresDialect{}, `SELECT 'a\' , E'b\' ?', ?, $tag$ ? $tag$, a$b FROM t -- ?`)
+	want4 := `SELECT 'a\' , E'b\' ?', $1, $tag$ ? $tag$, a$b FROM t -- ?`
+	if got4 != want4 {
+		t.Errorf("got %q; want %q", got4, want4)
+	}
+
+	got5, args5, err := ExpandIn("mysql", "SELECT * FROM t WHERE a = 'x\\' ?' AND id IN (?) # ?", []interface{}{In([]int{1, 2})}, EmptyInError)
+	want5 := "SELECT * FROM t WHERE a = 'x\\' ?' AND id IN (?, ?) # ?"
+	if err != nil || got5 != want5 || len(args5) != 2 {
+		t.Errorf("got %q, %v, %v; want %q, 2 args", got5, args5, err, want5)
+	}
+}
@@ -14,6 +14,20 @@ func IsBackup(mode int) bool {
 	return mode == Backup
 }
 
+// 角色名，writer、reader、backup
+func ModeName(mode int) string {
+	switch mode {
+	case Write:
+		return "writer"
+	case Read:
+		return "reader"
+	case Backup:
+		return "backup"
+	default:
+		return ""
+	}
+}
+
 func IsMode(num int) bool {
 	return num == Write || num == Read || num == Backup
 }
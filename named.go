@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 按驱动的方言将:name或@name改写为原生占位符，编号的占位符（如：$1）重复的参数复用同一编号，参数取自map[string]interface{}或结构体
+// 结构体按db标签取字段，无标签时按字段名（不区分大小写），db:"-"忽略
+// 字符串、标识符和注释内的:name不改写，PostgreSQL的::类型转换和MySQL的@@系统变量不视为参数
+func BindNamed(name string, query string, arg interface{}) (string, []interface{}, error) {
+	lookup, err := namedLookup(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	d := GetDialect(name)
+	numbered := d.Placeholder(1) != d.Placeholder(2)
+	tokens := lexSql(d, query)
+
+	var b strings.Builder
+	var args []interface{}
+	indexes := make(map[string]int)
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind == tokenPlaceholder {
+			return "", nil, fmt.Errorf("positional placeholder %s can't be mixed with named parameters", t.text)
+		}
+
+		if !isNamedPrefix(tokens, i) {
+			b.WriteString(t.text)
+			continue
+		}
+
+		param := tokens[i+1].text
+		i++
+
+		if numbered {
+			if n, ok := indexes[param]; ok {
+				b.WriteString(d.Placeholder(n))
+				continue
+			}
+		}
+
+		v, ok := lookup(param)
+		if !ok {
+			return "", nil, fmt.Errorf(`named parameter "%s" not found`, param)
+		}
+
+		args = append(args, v)
+		indexes[param] = len(args)
+		b.WriteString(d.Placeholder(len(args)))
+	}
+
+	return b.String(), args, nil
+}
+
+func ExecNamed(driver *Driver, query string, arg interface{}) (sql.Result, error) {
+	return ExecNamedContext(context.Background(), driver, query, arg)
+}
+
+func ExecNamedContext(ctx context.Context, driver *Driver, query string, arg interface{}) (sql.Result, error) {
+	if driver == nil {
+		return nil, ErrNilDriver
+	}
+
+	q, args, err := BindNamed(driver.GetName(), query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return ExecContext(ctx, driver, q, args...)
+}
+
+func QueryNamed(driver *Driver, query string, arg interface{}) ([]map[string]interface{}, error) {
+	return QueryNamedContext(context.Background(), driver, query, arg)
+}
+
+func QueryNamedContext(ctx context.Context, driver *Driver, query string, arg interface{}) ([]map[string]interface{}, error) {
+	if driver == nil {
+		return nil, ErrNilDriver
+	}
+
+	q, args, err := BindNamed(driver.GetName(), query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return QueryContext(ctx, driver, q, args...)
+}
+
+// :或@紧跟标识符，且@前不是@
+func isNamedPrefix(tokens []sqlToken, i int) bool {
+	t := tokens[i]
+	if t.kind != tokenOther || (t.text != ":" && t.text != "@") {
+		return false
+	}
+
+	if i+1 >= len(tokens) || tokens[i+1].kind != tokenWord {
+		return false
+	}
+
+	return t.text == ":" || i == 0 || tokens[i-1].text != "@"
+}
+
+func namedLookup(arg interface{}) (func(name string) (interface{}, bool), error) {
+	if m, ok := arg.(map[string]interface{}); ok {
+		return func(name string) (interface{}, bool) {
+			v, ok := m[name]
+			return v, ok
+		}, nil
+	}
+
+	v := indirect(reflect.ValueOf(arg))
+	if !v.IsValid() {
+		return nil, errors.New("arg can't be nil")
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("arg must be map[string]interface{} or struct, got %T", arg)
+	}
+
+	// 有标签的字段优先于无标签的字段，含嵌入结构体内的，同类同名时外层字段优先
+	tagged := make(map[string]interface{})
+	untagged := make(map[string]interface{})
+	walkStructFields(v, func(f structField) {
+		m := untagged
+		if f.tagged {
+			m = tagged
+		}
+
+		if _, ok := m[f.name]; !ok {
+			m[f.name] = f.value
+		}
+	})
+
+	return func(name string) (interface{}, bool) {
+		if v, ok := tagged[name]; ok {
+			return v, true
+		}
+
+		v, ok := untagged[strings.ToLower(name)]
+		return v, ok
+	}, nil
+}
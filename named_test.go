@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBindNamed(t *testing.T) {
+	query := "SELECT id::text, ':skip' FROM user WHERE name = :name OR nick = :name AND age > @age AND @@autocommit = 1"
+
+	got, args, err := BindNamed("postgres", query, map[string]interface{}{"name": "foo", "age": 18})
+	want := "SELECT id::text, ':skip' FROM user WHERE name = $1 OR nick = $1 AND age > $2 AND @@autocommit = 1"
+	if err != nil || got != want || fmt.Sprint(args) != "[foo 18]" {
+		t.Errorf("got %q, %v, %v; want %q, [foo 18]", got, args, err, want)
+	}
+
+	type base struct {
+		Age int
+	}
+
+	type user struct {
+		base
+		Name   string `db:"name"`
+		Secret string `db:"-"`
+	}
+
+	got2, args2, err := BindNamed("mysql", query, &user{base: base{Age: 20}, Name: "bar"})
+	want2 := "SELECT id::text, ':skip' FROM user WHERE name = ? OR nick = ? AND age > ? AND @@autocommit = 1"
+	if err != nil || got2 != want2 || fmt.Sprint(args2) != "[bar bar 20]" {
+		t.Errorf("got %q, %v, %v; want %q, [bar bar 20]", got2, args2, err, want2)
+	}
+
+	if _, _, err := BindNamed("mysql", "SELECT * FROM user WHERE secret = :secret", user{}); err == nil {
+		t.Errorf("got nil; want error")
+	}
+
+	type profile struct {
+		Nick string `db:"name"`
+	}
+
+	type account struct {
+		profile
+		Name string
+	}
+
+	got4, args4, err := BindNamed("mysql", "SELECT * FROM user WHERE name = :name", account{profile: profile{Nick: "tagged"}, Name: "untagged"})
+	if err != nil || got4 != "SELECT * FROM user WHERE name = ?" || fmt.Sprint(args4) != "[tagged]" {
+		t.Errorf("got %q, %v, %v; want embedded tagged field first", got4, args4, err)
+	}
+
+	got3, args3, err := BindNamed("postgres", "SELECT $$ :x $$, $fn$ @y $fn$, :id", map[string]interface{}{"id": 1})
+	want3 := "SELECT $$ :x $$, $fn$ @y $fn$, $1"
+	if err != nil || got3 != want3 || fmt.Sprint(args3) != "[1]" {
+		t.Errorf("got %q, %v, %v; want %q, [1]", got3, args3, err, want3)
+	}
+}
+
+func TestExecNamed(t *testing.T) {
+	setFakeResult(`UPDATE "user" SET nick = $1 WHERE name = $1 AND age > $2`, &fakeResult{affected: 2})
+	setFakeResult("SELECT id FROM user WHERE name = ? OR nick = ?", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}})
+
+	var args []interface{}
+	capture := func(d *Driver) *Driver {
+		_ = d.AddInterceptor(InterceptorFunc(func(c *Call, next Handler) error {
+			args = c.GetArgs()
+			return next(c)
+		}))
+
+		return d
+	}
+
+	// PostgreSQL下重复的名称绑定到同一个$n
+	pg := capture(newFakeNamedDriver("fakepg"))
+	r, err := ExecNamed(pg, `UPDATE "user" SET nick = :name WHERE name = :name AND age > :age`, map[string]interface{}{"name": "foo", "age": 18})
+	if err != nil || fmt.Sprint(args) != "[foo 18]" {
+		t.Fatalf("got %v, %v; want [foo 18], nil", args, err)
+	}
+
+	if n, _ := r.RowsAffected(); n != 2 {
+		t.Errorf("got %d; want 2", n)
+	}
+
+	// MySQL下重复的名称按出现次数绑定
+	mysql := capture(newFakeDriver())
+	got, err := QueryNamed(mysql, "SELECT id FROM user WHERE name = :name OR nick = :name", struct {
+		Name string `db:"name"`
+	}{Name: "bar"})
+
+	if err != nil || fmt.Sprint(got) != "[map[id:1]]" || fmt.Sprint(args) != "[bar bar]" {
+		t.Errorf("got %v, %v, %v; want [map[id:1]], [bar bar], nil", got, args, err)
+	}
+
+	// 缺少的名称不执行
+	args = nil
+	want := `named parameter "age" not found`
+	if _, err := ExecNamed(pg, "DELETE FROM user WHERE age > :age", map[string]interface{}{}); err == nil || err.Error() != want || args != nil {
+		t.Errorf("got %v, %v; want %q without executing", args, err, want)
+	}
+
+	if _, err := QueryNamedContext(context.Background(), mysql, "SELECT * FROM user WHERE id = :id", map[string]interface{}{"name": "foo"}); err == nil || args != nil {
+		t.Errorf("got %v, %v; want error without executing", args, err)
+	}
+
+	if _, err := QueryNamed(nil, "SELECT 1", nil); !errors.Is(err, ErrNilDriver) {
+		t.Errorf("got %v; want %v", err, ErrNilDriver)
+	}
+}
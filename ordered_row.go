@@ -0,0 +1,47 @@
+package database
+
+import "database/sql"
+
+// 保留列顺序的一行，用于表格输出
+type OrderedRow struct {
+	columns []string      // 列名，按查询的顺序
+	values  []interface{} // 值，与columns一一对应
+}
+
+// 按驱动返回的原值
+func ScanOrdered(rows *sql.Rows) ([]*OrderedRow, error) {
+	return (*Scanner)(nil).ScanOrdered(rows)
+}
+
+func (x *OrderedRow) GetColumns() []string {
+	return x.columns
+}
+
+func (x *OrderedRow) GetValues() []interface{} {
+	return x.values
+}
+
+func (x *OrderedRow) Len() int {
+	return len(x.values)
+}
+
+// 列名重复时，返回第一列
+func (x *OrderedRow) Get(column string) (interface{}, bool) {
+	for i, name := range x.columns {
+		if name == column {
+			return x.values[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// 转为map，列名重复时后面的列覆盖前面的列
+func (x *OrderedRow) ToMap() map[string]interface{} {
+	r := make(map[string]interface{}, len(x.columns))
+	for i, name := range x.columns {
+		r[name] = x.values[i]
+	}
+
+	return r
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 并发校验全部Driver的连接，超时时间timeout，按配置汇总失败原因
+// drivers: 标签，如：writer、reader、backup => Driver列表
+func pingDrivers(timeout time.Duration, drivers map[string][]*Driver) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var labels []string
+	for label := range drivers {
+		labels = append(labels, label)
+	}
+
+	sort.Strings(labels)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failures := make(map[*Driver]error)
+
+	for _, label := range labels {
+		for _, driver := range drivers[label] {
+			wg.Add(1)
+			go func(driver *Driver) {
+				defer wg.Done()
+
+				if err := driver.Ping(ctx); err != nil {
+					mu.Lock()
+					failures[driver] = err
+					mu.Unlock()
+				}
+			}(driver)
+		}
+	}
+
+	wg.Wait()
+
+	var r []error
+	for _, label := range labels {
+		for _, driver := range drivers[label] {
+			if err, ok := failures[driver]; ok {
+				r = append(r, driverError("ping", label, driver, err))
+			}
+		}
+	}
+
+	return errors.Join(r...)
+}
+
+// 标注操作、角色和地址，如：ping writer 127.0.0.1:3306/test: ...
+func driverError(op string, label string, driver *Driver, err error) error {
+	addr := ""
+	if s := driver.GetSchema(); s != nil {
+		addr = fmt.Sprintf("%s:%d/%s", s.GetHost(), s.GetPort(), s.GetDatabase())
+	}
+
+	if label == "" {
+		return fmt.Errorf("%s %s: %w", op, addr, err)
+	} else {
+		return fmt.Errorf("%s %s %s: %w", op, label, addr, err)
+	}
+}
+
+// 关闭全部Driver，用于Build失败时释放已打开的sql.DB
+func closeDrivers(drivers ...[]*Driver) {
+	for _, list := range drivers {
+		for _, driver := range list {
+			_ = driver.Close()
+		}
+	}
+}
@@ -7,27 +7,29 @@ import (
 )
 
 type Profile struct {
-	id                string        // 唯一标识
-	shardingFirst     int           // 分库开始，缺省：不分库
-	shardingLast      int           // 分库结束，缺省：不分库
-	shardingSeparator string        // 拼接库名和分库数
-	write             bool          // 是否是主库，写库，缺省：非主库
-	read              bool          // 是否是从库，只读库，缺省：非从库
-	backup            bool          // 是否是备库，复杂查询，缺省：非备库
-	host              string        // 域名或Ip
-	username          string        // 用户名
-	password          string        // 密码
-	driver            string        // 驱动名，mysql、postgres、...
-	proto             string        // 协议，如：tcp，缺省：define.Proto
-	port              int           // 端口，如：3306，缺省：define.Port
-	database          string        // 数据库名
-	charset           string        // 编码，缺省：define.Charset
-	collation         string        // 编码，缺省：define.Collation
-	timeout           string        // 系统默认：90s
-	maxOpen           int           // 最大连接数，缺省：0-不设置，无限制
-	maxIdle           int           // 最大空闲连接数，缺省：0-不设置，默认2
-	maxLifetime       time.Duration // 连接最大生命周期，缺省：0-不设置，永不过期
-	dsn               string        // data source name，建议置空，缺省：通过host、username、password、...拼接
+	id                string                // 唯一标识
+	shardingFirst     int                   // 分库开始，缺省：不分库
+	shardingLast      int                   // 分库结束，缺省：不分库
+	shardingSeparator string                // 拼接库名和分库数
+	write             bool                  // 是否是主库，写库，缺省：非主库
+	read              bool                  // 是否是从库，只读库，缺省：非从库
+	backup            bool                  // 是否是备库，复杂查询，缺省：非备库
+	host              string                // 域名或Ip
+	username          string                // 用户名
+	password          string                // 密码
+	driver            string                // 驱动名，mysql、postgres、...
+	proto             string                // 协议，如：tcp，缺省：define.Proto
+	port              int                   // 端口，如：3306，缺省：方言的端口
+	database          string                // 数据库名
+	charset           string                // 编码，缺省：define.Charset
+	collation         string                // 编码，缺省：define.Collation
+	timeout           string                // 系统默认：90s
+	maxOpen           int                   // 最大连接数，缺省：0-不设置，无限制
+	maxIdle           int                   // 最大空闲连接数，缺省：0-不设置，默认2
+	maxLifetime       time.Duration         // 连接最大生命周期，缺省：0-不设置，永不过期
+	dsn               string                // data source name，建议置空，缺省：通过host、username、password、...拼接
+	slowThreshold     time.Duration         // 慢查询阈值，如：200ms，缺省：0-按角色的缺省阈值
+	slowThresholds    map[int]time.Duration // 角色 => 慢查询阈值，优先于slowThreshold，如：read_slow_threshold = 1s
 }
 
 func NewProfile(data map[string]string) (*Profile, error) {
@@ -40,7 +42,7 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if n, err := strconv.ParseInt(data[ProfileShardingFirst], 10, 32); err == nil {
 			shardingFirst = int(n)
 		} else {
-			return nil, err
+			return nil, &ProfileError{Key: ProfileShardingFirst, Value: data[ProfileShardingFirst], Err: err}
 		}
 	}
 
@@ -49,7 +51,7 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if n, err := strconv.ParseInt(data[ProfileShardingLast], 10, 32); err == nil {
 			shardingLast = int(n)
 		} else {
-			return nil, err
+			return nil, &ProfileError{Key: ProfileShardingLast, Value: data[ProfileShardingLast], Err: err}
 		}
 	}
 
@@ -58,7 +60,7 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if b, err := strconv.ParseBool(data[ProfileWrite]); err == nil {
 			write = b
 		} else {
-			return nil, err
+			return nil, &ProfileError{Key: ProfileWrite, Value: data[ProfileWrite], Err: err}
 		}
 	}
 
@@ -67,7 +69,7 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if b, err := strconv.ParseBool(data[ProfileRead]); err == nil {
 			read = b
 		} else {
-			return nil, err
+			return nil, &ProfileError{Key: ProfileRead, Value: data[ProfileRead], Err: err}
 		}
 	}
 
@@ -76,7 +78,7 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if b, err := strconv.ParseBool(data[ProfileBackup]); err == nil {
 			backup = b
 		} else {
-			return nil, err
+			return nil, &ProfileError{Key: ProfileBackup, Value: data[ProfileBackup], Err: err}
 		}
 	}
 
@@ -85,7 +87,7 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if p, err := strconv.ParseInt(data[ProfilePort], 10, 32); err == nil {
 			port = int(p)
 		} else {
-			return nil, err
+			return nil, &ProfileError{Key: ProfilePort, Value: data[ProfilePort], Err: err}
 		}
 	}
 
@@ -94,7 +96,7 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if n, err := strconv.ParseInt(data[ProfileMaxOpen], 10, 32); err == nil {
 			maxOpen = int(n)
 		} else {
-			return nil, err
+			return nil, &ProfileError{Key: ProfileMaxOpen, Value: data[ProfileMaxOpen], Err: err}
 		}
 	}
 
@@ -103,7 +105,7 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if n, err := strconv.ParseInt(data[ProfileMaxIdle], 10, 32); err == nil {
 			maxIdle = int(n)
 		} else {
-			return nil, err
+			return nil, &ProfileError{Key: ProfileMaxIdle, Value: data[ProfileMaxIdle], Err: err}
 		}
 	}
 
@@ -112,8 +114,28 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		if d, err := strconv.ParseInt(data[ProfileMaxLifetime], 10, 64); err == nil {
 			maxLifetime = time.Duration(d)
 		} else {
+			return nil, &ProfileError{Key: ProfileMaxLifetime, Value: data[ProfileMaxLifetime], Err: err}
+		}
+	}
+
+	slowThreshold, err := parseSlowThreshold(data, ProfileSlowThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	slowThresholds := make(map[int]time.Duration)
+	for _, r := range []struct {
+		mode int
+		key  string
+	}{{Write, ProfileWriteSlowThreshold}, {Read, ProfileReadSlowThreshold}, {Backup, ProfileBackupSlowThreshold}} {
+		d, err := parseSlowThreshold(data, r.key)
+		if err != nil {
 			return nil, err
 		}
+
+		if d > 0 {
+			slowThresholds[r.mode] = d
+		}
 	}
 
 	id := data[ProfileId]
@@ -151,9 +173,29 @@ func NewProfile(data map[string]string) (*Profile, error) {
 		maxIdle:           maxIdle,
 		maxLifetime:       maxLifetime,
 		dsn:               dsn,
+		slowThreshold:     slowThreshold,
+		slowThresholds:    slowThresholds,
 	}, nil
 }
 
+// 慢查询阈值，如：200ms，无单位时按纳秒
+func parseSlowThreshold(data map[string]string, key string) (time.Duration, error) {
+	if data[key] == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(data[key])
+	if err == nil {
+		return d, nil
+	}
+
+	if n, err2 := strconv.ParseInt(data[key], 10, 64); err2 == nil {
+		return time.Duration(n), nil
+	}
+
+	return 0, &ProfileError{Key: key, Value: data[key], Err: err}
+}
+
 func (x *Profile) GetId() string {
 	return x.id
 }
@@ -166,6 +208,11 @@ func (x *Profile) GetShardingLast() int {
 	return x.shardingLast
 }
 
+// 是否分库，sharding_first或sharding_last已设置
+func (x *Profile) IsSharding() bool {
+	return x.shardingFirst >= 0 || x.shardingLast >= 0
+}
+
 func (x *Profile) GetShardingSeparator() string {
 	return x.shardingSeparator
 }
@@ -237,3 +284,12 @@ func (x *Profile) GetMaxLifetime() time.Duration {
 func (x *Profile) GetDsn() string {
 	return x.dsn
 }
+
+func (x *Profile) GetSlowThreshold() time.Duration {
+	return x.slowThreshold
+}
+
+// 按角色的慢查询阈值，如：write_slow_threshold，未配置时为0
+func (x *Profile) GetSlowThresholds() map[int]time.Duration {
+	return x.slowThresholds
+}
@@ -1,25 +1,29 @@
 package database
 
 const (
-	ProfileId                = "id"
-	ProfileShardingFirst     = "sharding_first"
-	ProfileShardingLast      = "sharding_last"
-	ProfileShardingSeparator = "sharding_separator"
-	ProfileWrite             = "write"
-	ProfileRead              = "read"
-	ProfileBackup            = "backup"
-	ProfileHost              = "host"
-	ProfileUsername          = "username"
-	ProfilePassword          = "password"
-	ProfileDriver            = "driver"
-	ProfileProto             = "proto"
-	ProfilePort              = "port"
-	ProfileDatabase          = "database"
-	ProfileCharset           = "charset"
-	ProfileCollation         = "collation"
-	ProfileTimeout           = "timeout"
-	ProfileMaxOpen           = "max_open"
-	ProfileMaxIdle           = "max_idle"
-	ProfileMaxLifetime       = "max_lifetime"
-	ProfileDsn               = "dsn"
+	ProfileId                  = "id"
+	ProfileShardingFirst       = "sharding_first"
+	ProfileShardingLast        = "sharding_last"
+	ProfileShardingSeparator   = "sharding_separator"
+	ProfileWrite               = "write"
+	ProfileRead                = "read"
+	ProfileBackup              = "backup"
+	ProfileHost                = "host"
+	ProfileUsername            = "username"
+	ProfilePassword            = "password"
+	ProfileDriver              = "driver"
+	ProfileProto               = "proto"
+	ProfilePort                = "port"
+	ProfileDatabase            = "database"
+	ProfileCharset             = "charset"
+	ProfileCollation           = "collation"
+	ProfileTimeout             = "timeout"
+	ProfileMaxOpen             = "max_open"
+	ProfileMaxIdle             = "max_idle"
+	ProfileMaxLifetime         = "max_lifetime"
+	ProfileDsn                 = "dsn"
+	ProfileSlowThreshold       = "slow_threshold"
+	ProfileWriteSlowThreshold  = "write_slow_threshold"
+	ProfileReadSlowThreshold   = "read_slow_threshold"
+	ProfileBackupSlowThreshold = "backup_slow_threshold"
 )
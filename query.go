@@ -0,0 +1,66 @@
+package database
+
+import "context"
+
+// 查询多行，关闭rows的错误并入返回的错误，无结果时返回"empty result"
+func Query(driver *Driver, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return QueryContext(context.Background(), driver, query, args...)
+}
+
+func QueryContext(ctx context.Context, driver *Driver, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	result, err, closeErr := FindContext(ctx, driver, query, args...)
+	if err = joinClose(err, closeErr); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// 查询首行，关闭rows的错误并入返回的错误，无结果时返回"empty result"
+func QueryFirst(driver *Driver, query string, args ...interface{}) (map[string]interface{}, error) {
+	return QueryFirstContext(context.Background(), driver, query, args...)
+}
+
+func QueryFirstContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (map[string]interface{}, error) {
+	result, err, closeErr := first(ctx, MethodFirst, driver, query, args)
+	if err = joinClose(err, closeErr); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// "AS 'aggregate'" must be contained in Query
+// 关闭rows的错误并入返回的错误，无结果或NULL时返回nil
+func QueryAggregate(driver *Driver, query string, args ...interface{}) (interface{}, error) {
+	return QueryAggregateContext(context.Background(), driver, query, args...)
+}
+
+func QueryAggregateContext(ctx context.Context, driver *Driver, query string, args ...interface{}) (interface{}, error) {
+	result, err, closeErr := aggregateContext(ctx, driver, query, args)
+	if err = joinClose(err, closeErr); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// "AS 'aggregate'" must be contained in Query
+func QueryAggregateInt(driver *Driver, query string, args ...interface{}) (int64, error) {
+	r, err := QueryAggregate(driver, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return aggregateInt(r)
+}
+
+// "AS 'aggregate'" must be contained in Query
+func QueryAggregateFloat(driver *Driver, query string, args ...interface{}) (float64, error) {
+	r, err := QueryAggregate(driver, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return aggregateFloat(r)
+}
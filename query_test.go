@@ -0,0 +1,31 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	broken := errors.New("broken pipe")
+	setFakeResult("SELECT id FROM user", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}})
+	setFakeResult("SELECT id FROM order", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}, iterErr: broken})
+	setFakeResult("SELECT COUNT(1) AS 'aggregate' FROM user", &fakeResult{columns: []string{AggregateAlias}, rows: [][]driver.Value{{[]byte("2")}}})
+
+	d := newFakeDriver()
+
+	got, err := Query(d, "SELECT id FROM user")
+	if err != nil || len(got) != 2 {
+		t.Errorf("got %v, %v; want 2 rows, nil", got, err)
+	}
+
+	got2, err := Query(d, "SELECT id FROM order")
+	if !errors.Is(err, broken) || got2 != nil {
+		t.Errorf("got %v, %v; want nil, %v", got2, err, broken)
+	}
+
+	got3, err := QueryAggregateInt(d, "SELECT COUNT(1) AS 'aggregate' FROM user")
+	if err != nil || got3 != 2 {
+		t.Errorf("got %v, %v; want 2, nil", got3, err)
+	}
+}
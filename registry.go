@@ -0,0 +1,356 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Registry struct {
+	mu             sync.RWMutex                          // ensures atomic writes; protects drivers & shardings
+	reloadMu       sync.Mutex                            // ensures serial reloads
+	drivers        map[string]*Drivers                   // 唯一标识 => 主从库
+	shardings      map[string]*Sharding                  // 唯一标识 => 分库
+	dsnJoiner      func(s *Schema) string                // dsn拼接函数，热加载时沿用
+	shardingJoiner func(database string, num int) string // sharding拼接函数，热加载时沿用
+	lazy           bool                                  // 懒加载，热加载时沿用
+	pingTimeout    time.Duration                         // 校验连接的超时时间，热加载时沿用
+	interceptors   []Interceptor                         // 拦截器，热加载时沿用
+	retry          *Retry                                // 重试策略，热加载时沿用
+	breaker        *BreakerBuilder                       // 熔断器，热加载时沿用
+	scanner        *Scanner                              // 查询结果转换，热加载时沿用
+	closed         bool                                  // 已关闭，不再分配主从库和分库
+}
+
+// 按唯一标识分组Profile，分库用ShardingBuilder，不分库用DriversBuilder
+func NewRegistry(profiles []*Profile) (*Registry, error) {
+	builder := &RegistryBuilder{}
+	for _, p := range profiles {
+		if err := builder.AddProfile(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return builder.Build()
+}
+
+func (x *Registry) Get(id string) (*Drivers, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if x.closed {
+		return nil, newKindError(ErrShutdown, "registry has been shut down")
+	}
+
+	if d, ok := x.drivers[id]; ok {
+		return d, nil
+	}
+
+	return nil, newKindError(ErrNotFound, `drivers "%s" not found`, id)
+}
+
+func (x *Registry) GetSharding(id string) (*Sharding, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if x.closed {
+		return nil, newKindError(ErrShutdown, "registry has been shut down")
+	}
+
+	if s, ok := x.shardings[id]; ok {
+		return s, nil
+	}
+
+	return nil, newKindError(ErrNotFound, `sharding "%s" not found`, id)
+}
+
+// 全部唯一标识，含主从库和分库，升序
+func (x *Registry) GetIds() []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var r []string
+	for id := range x.drivers {
+		r = append(r, id)
+	}
+
+	for id := range x.shardings {
+		r = append(r, id)
+	}
+
+	sort.Strings(r)
+	return r
+}
+
+func (x *Registry) AddDrivers(d *Drivers) error {
+	if d == nil {
+		return errors.New("drivers can't be nil")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.checkId(d.GetId()); err != nil {
+		return err
+	}
+
+	if x.drivers == nil {
+		x.drivers = make(map[string]*Drivers)
+	}
+
+	x.drivers[d.GetId()] = d
+	return nil
+}
+
+func (x *Registry) AddSharding(s *Sharding) error {
+	if s == nil {
+		return errors.New("sharding can't be nil")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.checkId(s.GetId()); err != nil {
+		return err
+	}
+
+	if x.shardings == nil {
+		x.shardings = make(map[string]*Sharding)
+	}
+
+	x.shardings[s.GetId()] = s
+	return nil
+}
+
+// 关闭全部sql.DB
+func (x *Registry) Close() []error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var r []error
+
+	for _, d := range x.drivers {
+		r = append(r, d.Close()...)
+	}
+
+	for _, s := range x.shardings {
+		r = append(r, s.Close()...)
+	}
+
+	return r
+}
+
+func (x *Registry) checkId(id string) error {
+	if id == "" {
+		return errors.New("id can't be empty")
+	}
+
+	if _, ok := x.drivers[id]; ok {
+		return fmt.Errorf(`id "%s" has been contained in drivers`, id)
+	}
+
+	if _, ok := x.shardings[id]; ok {
+		return fmt.Errorf(`id "%s" has been contained in shardings`, id)
+	}
+
+	return nil
+}
+
+type RegistryBuilder struct {
+	mu             sync.Mutex                            // ensures atomic writes; protects the following fields
+	ids            []string                              // 唯一标识，按添加顺序
+	profiles       map[string][]*Profile                 // 唯一标识 => 配置列表
+	dsnJoiner      func(s *Schema) string                // dsn拼接函数
+	shardingJoiner func(database string, num int) string // sharding拼接函数
+	lazy           bool                                  // 懒加载，首次使用才调用sql.Open，缺省：false
+	pingTimeout    time.Duration                         // Build时并发校验全部连接的超时时间，缺省：0-不校验
+	interceptors   []Interceptor                         // 拦截器，用于全部Driver
+	retry          *Retry                                // 重试策略，主从库换Driver重试，分库在同一Driver上重试
+	breaker        *BreakerBuilder                       // 熔断器，每个Driver一个
+	scanner        *Scanner                              // 查询结果转换，用于全部Driver
+}
+
+func (x *RegistryBuilder) Build() (*Registry, error) {
+	if len(x.ids) == 0 {
+		return nil, errors.New("profiles can't be empty")
+	}
+
+	r := &Registry{
+		dsnJoiner:      x.dsnJoiner,
+		shardingJoiner: x.shardingJoiner,
+		lazy:           x.lazy,
+		pingTimeout:    x.pingTimeout,
+		interceptors:   x.interceptors,
+		retry:          x.retry,
+		breaker:        x.breaker,
+		scanner:        x.scanner,
+	}
+
+	for _, id := range x.ids {
+		if err := x.build(r, id, x.profiles[id]); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (x *RegistryBuilder) build(r *Registry, id string, profiles []*Profile) error {
+	sharding := profiles[0].IsSharding()
+	for _, p := range profiles[1:] {
+		if p.IsSharding() != sharding {
+			return fmt.Errorf(`id "%s" can't mix sharding and non-sharding profiles`, id)
+		}
+	}
+
+	if sharding {
+		builder := &ShardingBuilder{}
+		builder.
+			SetDsnJoiner(x.dsnJoiner).
+			SetShardingJoiner(x.shardingJoiner).
+			SetLazy(x.lazy).
+			SetPingTimeout(x.pingTimeout).
+			SetRetry(x.retry).
+			SetBreaker(x.breaker).
+			SetScanner(x.scanner)
+
+		for _, i := range x.interceptors {
+			builder.AddInterceptor(i)
+		}
+
+		for _, p := range profiles {
+			if err := builder.AddProfile(p); err != nil {
+				return fmt.Errorf(`id "%s": %w`, id, err)
+			}
+		}
+
+		s, err := builder.Build()
+		if err != nil {
+			return fmt.Errorf(`id "%s": %w`, id, err)
+		}
+
+		return r.AddSharding(s)
+	}
+
+	builder := &DriversBuilder{}
+	builder.
+		SetDsnJoiner(x.dsnJoiner).
+		SetLazy(x.lazy).
+		SetPingTimeout(x.pingTimeout).
+		SetRetry(x.retry).
+		SetBreaker(x.breaker).
+		SetScanner(x.scanner)
+
+	for _, i := range x.interceptors {
+		builder.AddInterceptor(i)
+	}
+
+	for _, p := range profiles {
+		if err := builder.AddProfile(p); err != nil {
+			return fmt.Errorf(`id "%s": %w`, id, err)
+		}
+	}
+
+	d, err := builder.Build()
+	if err != nil {
+		return fmt.Errorf(`id "%s": %w`, id, err)
+	}
+
+	return r.AddDrivers(d)
+}
+
+func (x *RegistryBuilder) AddProfile(p *Profile) error {
+	if p == nil {
+		return errors.New("profile can't be nil")
+	}
+
+	id := strings.TrimSpace(p.GetId())
+	if id == "" {
+		return errors.New("id can't be empty")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.profiles == nil {
+		x.profiles = make(map[string][]*Profile)
+	}
+
+	if _, ok := x.profiles[id]; !ok {
+		x.ids = append(x.ids, id)
+	}
+
+	x.profiles[id] = append(x.profiles[id], p)
+	return nil
+}
+
+func (x *RegistryBuilder) SetDsnJoiner(f func(s *Schema) string) *RegistryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.dsnJoiner = f
+	return x
+}
+
+func (x *RegistryBuilder) SetShardingJoiner(f func(database string, num int) string) *RegistryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.shardingJoiner = f
+	return x
+}
+
+func (x *RegistryBuilder) SetLazy(b bool) *RegistryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.lazy = b
+	return x
+}
+
+func (x *RegistryBuilder) SetPingTimeout(d time.Duration) *RegistryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.pingTimeout = d
+	return x
+}
+
+func (x *RegistryBuilder) SetRetry(r *Retry) *RegistryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.retry = r
+	return x
+}
+
+func (x *RegistryBuilder) SetBreaker(b *BreakerBuilder) *RegistryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.breaker = b
+	return x
+}
+
+func (x *RegistryBuilder) SetScanner(s *Scanner) *RegistryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.scanner = s
+	return x
+}
+
+func (x *RegistryBuilder) AddInterceptor(i Interceptor) *RegistryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if i != nil {
+		x.interceptors = append(x.interceptors, i)
+	}
+
+	return x
+}
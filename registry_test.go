@@ -0,0 +1,287 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistryBuilder(t *testing.T) {
+	profiles := []map[string]string{
+		{ProfileId: "user", ProfileDriver: "mysql", ProfileHost: "127.0.0.1", ProfileDatabase: "user", ProfileUsername: "root", ProfileWrite: "true"},
+		{ProfileId: "user", ProfileDriver: "mysql", ProfileHost: "127.0.0.2", ProfileDatabase: "user", ProfileUsername: "root", ProfileRead: "true"},
+		{ProfileId: "order", ProfileDriver: "mysql", ProfileHost: "127.0.0.3", ProfileDatabase: "order", ProfileUsername: "root", ProfileShardingFirst: "0", ProfileShardingLast: "3"},
+	}
+
+	b := &RegistryBuilder{}
+	b.SetLazy(true)
+	for _, data := range profiles {
+		p, err := NewProfile(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := b.AddProfile(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, got := r.Get("user")
+	if got != nil {
+		t.Errorf("got %q; want nil", got)
+	}
+
+	if len(d.GetWriters()) != 1 || len(d.GetReaders()) != 1 {
+		t.Errorf("got %d writers, %d readers; want 1, 1", len(d.GetWriters()), len(d.GetReaders()))
+	}
+
+	s, got2 := r.GetSharding("order")
+	if got2 != nil {
+		t.Errorf("got %q; want nil", got2)
+	}
+
+	if s.GetSize() != 4 {
+		t.Errorf("got %d; want 4", s.GetSize())
+	}
+
+	_, got3 := r.Get("order")
+	want3 := `drivers "order" not found`
+	if got3 == nil || got3.Error() != want3 {
+		t.Errorf("got %v; want %q", got3, want3)
+	}
+
+	if got4 := r.Close(); len(got4) != 0 {
+		t.Errorf("got %v; want empty", got4)
+	}
+}
+
+func TestRegistryShutdown(t *testing.T) {
+	profiles := []map[string]string{
+		{ProfileId: "user", ProfileDriver: "fake", ProfileHost: "127.0.0.1", ProfileDatabase: "user", ProfileUsername: "root", ProfileWrite: "true"},
+		{ProfileId: "order", ProfileDriver: "fake", ProfileHost: "127.0.0.3", ProfileDatabase: "order", ProfileUsername: "root", ProfileShardingFirst: "0", ProfileShardingLast: "1"},
+	}
+
+	var list []*Profile
+	for _, data := range profiles {
+		p, err := NewProfile(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		list = append(list, p)
+	}
+
+	r, err := NewRegistry(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, _ := r.Get("user")
+	writer, _ := d.GetWriter()
+	_, release, err := writer.acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	got := r.Shutdown(ctx)
+	if !errors.Is(got, context.DeadlineExceeded) {
+		t.Errorf("got %v; want context.DeadlineExceeded", got)
+	}
+
+	release()
+
+	_, got2 := d.GetWriter()
+	want2 := "drivers has been shut down"
+	if got2 == nil || got2.Error() != want2 {
+		t.Errorf("got %v; want %q", got2, want2)
+	}
+
+	_, got3 := r.Get("user")
+	if !errors.Is(got3, ErrShutdown) {
+		t.Errorf("got %v; want ErrShutdown", got3)
+	}
+
+	_, got4 := r.Reload(list)
+	if !errors.Is(got4, ErrShutdown) {
+		t.Errorf("got %v; want ErrShutdown", got4)
+	}
+}
+
+func TestRegistryReload(t *testing.T) {
+	var profiles []*Profile
+	for _, data := range []map[string]string{
+		{ProfileId: "user", ProfileDriver: "mysql", ProfileHost: "127.0.0.1", ProfileDatabase: "user", ProfileUsername: "root", ProfileWrite: "true"},
+		{ProfileId: "user", ProfileDriver: "mysql", ProfileHost: "127.0.0.2", ProfileDatabase: "user", ProfileUsername: "root", ProfileRead: "true"},
+		{ProfileId: "log", ProfileDriver: "mysql", ProfileHost: "127.0.0.3", ProfileDatabase: "log", ProfileUsername: "root", ProfileWrite: "true"},
+	} {
+		p, err := NewProfile(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	b := &RegistryBuilder{}
+	b.SetLazy(true)
+	for _, p := range profiles {
+		_ = b.AddProfile(p)
+	}
+
+	r, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, _ := r.Get("user")
+	writer, _ := d.GetWriter()
+
+	moved, _ := NewProfile(map[string]string{ProfileId: "user", ProfileDriver: "mysql", ProfileHost: "127.0.0.4", ProfileDatabase: "user", ProfileUsername: "root", ProfileRead: "true"})
+
+	got, err := r.Reload([]*Profile{profiles[0], moved})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.GetOpened() != 1 || got.GetKept() != 1 || got.GetClosed() != 2 {
+		t.Errorf("got opened %d, kept %d, closed %d; want 1, 1, 2", got.GetOpened(), got.GetKept(), got.GetClosed())
+	}
+
+	if len(got.GetRemoved()) != 1 || got.GetRemoved()[0] != "log" {
+		t.Errorf("got removed %v; want [log]", got.GetRemoved())
+	}
+
+	if writer2, _ := d.GetWriter(); writer2 != writer {
+		t.Errorf("got a new writer; want the unchanged writer kept")
+	}
+
+	if reader, _ := d.GetReader(); reader.GetSchema().GetHost() != "127.0.0.4" {
+		t.Errorf("got reader host %q; want %q", reader.GetSchema().GetHost(), "127.0.0.4")
+	}
+
+	if _, got2 := r.Get("log"); got2 == nil {
+		t.Errorf("got nil; want error")
+	}
+}
+
+func TestDriversReloadRetire(t *testing.T) {
+	newBuilder := func(host string) *DriversBuilder {
+		b := &DriversBuilder{}
+		_ = b.SetId("user")
+		_ = b.SetName("fake")
+		_ = b.AddWriter(&Schema{host: host, port: Port, database: "user", username: "root"})
+		b.SetLazy(true)
+		return b
+	}
+
+	d, err := newBuilder("127.0.0.1").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, _ := d.GetWriter()
+	if _, err := d.Reload(newBuilder("127.0.0.2")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, got := old.acquire()
+	if !errors.Is(got, ErrShutdown) {
+		t.Errorf("got %v; want ErrShutdown", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = d.Reload(newBuilder(fmt.Sprintf("127.0.1.%d", i%2)))
+		}(i)
+	}
+
+	wg.Wait()
+
+	if got2 := len(d.GetWriters()); got2 != 1 {
+		t.Errorf("got %d writers; want 1", got2)
+	}
+
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, got3 := d.Reload(newBuilder("127.0.0.3"))
+	if !errors.Is(got3, ErrShutdown) {
+		t.Errorf("got %v; want ErrShutdown", got3)
+	}
+}
+
+func TestShardingReload(t *testing.T) {
+	newBuilder := func(hosts ...string) *ShardingBuilder {
+		b := &ShardingBuilder{}
+		_ = b.SetId("order")
+		_ = b.SetName("fake")
+		for num, host := range hosts {
+			_ = b.SetSchema(num, &Schema{host: host, port: Port, database: "order", username: "root"})
+		}
+
+		b.SetLazy(true)
+		return b
+	}
+
+	b := newBuilder("127.0.0.1", "127.0.0.2")
+	s, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := s.Reload(b)
+		if err != nil || got.GetOpened() != 0 || got.GetKept() != 2 {
+			t.Fatalf("reload %d got %v, %v; want 2 kept", i, got, err)
+		}
+	}
+
+	first, _ := s.GetDriver(0)
+	if db := first.GetSchema().GetDatabase(); db != "order_0" {
+		t.Errorf("got %q; want %q", db, "order_0")
+	}
+
+	got2, err := s.Reload(newBuilder("127.0.0.1", "127.0.0.3"))
+	if err != nil || got2.GetOpened() != 1 || got2.GetClosed() != 1 || got2.GetKept() != 1 {
+		t.Errorf("got %v, %v; want opened 1, closed 1, kept 1", got2, err)
+	}
+
+	if d, _ := s.GetDriver(0); d != first {
+		t.Errorf("got a new driver for shard 0; want it kept")
+	}
+
+	if d, _ := s.GetDriver(1); d.GetSchema().GetHost() != "127.0.0.3" || d.GetNum() != 1 {
+		t.Errorf("got host %q num %d; want 127.0.0.3 and 1", d.GetSchema().GetHost(), d.GetNum())
+	}
+
+	if _, err := s.Reload(newBuilder("127.0.0.1")); err == nil {
+		t.Errorf("got nil; want size change error")
+	}
+
+	if _, err := s.Reload(nil); err == nil {
+		t.Errorf("got nil; want nil builder error")
+	}
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Reload(b); !errors.Is(err, ErrShutdown) {
+		t.Errorf("got %v; want ErrShutdown", err)
+	}
+}
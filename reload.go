@@ -0,0 +1,525 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// 热加载结果
+type ReloadResult struct {
+	added   []string // 新增的唯一标识
+	removed []string // 删除的唯一标识
+	changed []string // 有Driver新建或关闭的唯一标识
+	opened  int      // 新建的Driver数
+	closed  int      // 待关闭的Driver数，等待进行中的调用结束后关闭
+	kept    int      // 配置未变更，复用的Driver数
+}
+
+func (x *ReloadResult) ToString() string {
+	return fmt.Sprintf("added:   %v\n"+
+		"removed: %v\n"+
+		"changed: %v\n"+
+		"opened:  %v\n"+
+		"closed:  %v\n"+
+		"kept:    %v\n",
+		x.GetAdded(), x.GetRemoved(), x.GetChanged(), x.GetOpened(), x.GetClosed(), x.GetKept(),
+	)
+}
+
+func (x *ReloadResult) GetAdded() []string {
+	return x.added
+}
+
+func (x *ReloadResult) GetRemoved() []string {
+	return x.removed
+}
+
+func (x *ReloadResult) GetChanged() []string {
+	return x.changed
+}
+
+func (x *ReloadResult) GetOpened() int {
+	return x.opened
+}
+
+func (x *ReloadResult) GetClosed() int {
+	return x.closed
+}
+
+func (x *ReloadResult) GetKept() int {
+	return x.kept
+}
+
+// 热加载计划，先新建Driver，全部成功后再原子替换
+type reloadPlan struct {
+	commit  func()              // 替换Driver列表
+	opened  []*Driver           // 新建的Driver
+	closed  []*Driver           // 替换后待关闭的Driver
+	kept    int                 // 复用的Driver数
+	schemas map[*Driver]*Schema // 复用的Driver => 新配置，替换时一并更新，如：慢查询阈值
+}
+
+// 放弃替换，关闭新建的Driver
+func (x *reloadPlan) abort() {
+	closeDrivers(x.opened)
+}
+
+// 复用Driver，替换时更新为新配置
+func (x *reloadPlan) reuse(d *Driver, s *Schema) {
+	if x.schemas == nil {
+		x.schemas = make(map[*Driver]*Schema)
+	}
+
+	x.schemas[d] = s
+	x.kept++
+}
+
+// 更新复用的Driver的配置
+func (x *reloadPlan) apply() {
+	for d, s := range x.schemas {
+		d.setSchema(s)
+	}
+}
+
+// 按新配置热加载，仅为变更的配置新建Driver，进行中的调用结束后再关闭旧Driver
+func (x *Drivers) Reload(b *DriversBuilder) (*ReloadResult, error) {
+	x.reloadMu.Lock()
+	defer x.reloadMu.Unlock()
+
+	plan, err := x.prepare(b)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.commit()
+	retireDrivers(plan.closed)
+
+	r := &ReloadResult{opened: len(plan.opened), closed: len(plan.closed), kept: plan.kept}
+	if r.opened > 0 || r.closed > 0 {
+		r.changed = []string{x.id}
+	}
+
+	return r, nil
+}
+
+// 需持有reloadMu，直到commit或abort
+func (x *Drivers) prepare(b *DriversBuilder) (*reloadPlan, error) {
+	if b == nil {
+		return nil, errors.New("builder can't be nil")
+	}
+
+	if b.id != x.id {
+		return nil, fmt.Errorf(`id "%s" must be equal than x.id "%s"`, b.id, x.id)
+	}
+
+	if b.name == "" {
+		return nil, errors.New("name can't be empty")
+	}
+
+	if b.lazy && b.pingTimeout > 0 {
+		return nil, errors.New("lazy and ping timeout can't be both set")
+	}
+
+	if len(b.writers) == 0 && len(b.readers) == 0 && len(b.backups) == 0 {
+		return nil, errors.New("no driver, writer & reader & backup is nil")
+	}
+
+	x.mu.RLock()
+	closed := x.closed
+	writers, readers, backups := x.writers, x.readers, x.backups
+	x.mu.RUnlock()
+
+	if closed {
+		return nil, newKindError(ErrShutdown, "drivers has been shut down")
+	}
+
+	builder := b.driverBuilder()
+	plan := &reloadPlan{}
+
+	w, err := plan.match(builder, writers, b.writers, b.id, Write)
+	if err != nil {
+		plan.abort()
+		return nil, err
+	}
+
+	r, err := plan.match(builder, readers, b.readers, b.id, Read)
+	if err != nil {
+		plan.abort()
+		return nil, err
+	}
+
+	bk, err := plan.match(builder, backups, b.backups, b.id, Backup)
+	if err != nil {
+		plan.abort()
+		return nil, err
+	}
+
+	if b.pingTimeout > 0 && len(plan.opened) > 0 {
+		if err := pingDrivers(b.pingTimeout, map[string][]*Driver{"": plan.opened}); err != nil {
+			plan.abort()
+			return nil, err
+		}
+	}
+
+	plan.commit = func() {
+		x.mu.Lock()
+		defer x.mu.Unlock()
+
+		x.writers, x.readers, x.backups = w, r, bk
+		plan.apply()
+	}
+
+	return plan, nil
+}
+
+// 配置未变更的复用旧Driver，变更的新建Driver，多余的旧Driver待关闭
+func (x *reloadPlan) match(builder *DriverBuilder, drivers []*Driver, schemas []*Schema, id string, mode int) ([]*Driver, error) {
+	pool := make(map[string][]*Driver, len(drivers))
+	for _, d := range drivers {
+		pool[d.key()] = append(pool[d.key()], d)
+	}
+
+	used := make(map[*Driver]bool, len(drivers))
+
+	var r []*Driver
+	for _, s := range schemas {
+		if s == nil {
+			return nil, errors.New("schema can't be nil")
+		}
+
+		s = s.withPort(GetDialect(builder.name))
+		key := schemaKey(builder.name, builder.joiner(s), s)
+		if list := pool[key]; len(list) > 0 {
+			x.reuse(list[0], s)
+			r = append(r, list[0])
+			used[list[0]] = true
+			pool[key] = list[1:]
+			continue
+		}
+
+		d, err := builder.SetSchema(s).Build()
+		if err != nil {
+			return nil, err
+		}
+
+		r = append(r, d.label(id, mode, -1))
+		x.opened = append(x.opened, d)
+	}
+
+	for _, d := range drivers {
+		if !used[d] {
+			x.closed = append(x.closed, d)
+		}
+	}
+
+	return r, nil
+}
+
+// 按新配置热加载，分库数不可变更，仅为变更的库新建Driver
+func (x *Sharding) Reload(b *ShardingBuilder) (*ReloadResult, error) {
+	x.reloadMu.Lock()
+	defer x.reloadMu.Unlock()
+
+	plan, err := x.prepare(b)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.commit()
+	retireDrivers(plan.closed)
+
+	r := &ReloadResult{opened: len(plan.opened), closed: len(plan.closed), kept: plan.kept}
+	if r.opened > 0 || r.closed > 0 {
+		r.changed = []string{x.id}
+	}
+
+	return r, nil
+}
+
+// 需持有reloadMu，直到commit或abort
+func (x *Sharding) prepare(b *ShardingBuilder) (*reloadPlan, error) {
+	if b == nil {
+		return nil, errors.New("builder can't be nil")
+	}
+
+	if b.id != x.id {
+		return nil, fmt.Errorf(`id "%s" must be equal than x.id "%s"`, b.id, x.id)
+	}
+
+	schemas, err := b.shardSchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	x.mu.RLock()
+	closed := x.closed
+	drivers := x.drivers
+	x.mu.RUnlock()
+
+	if closed {
+		return nil, newKindError(ErrShutdown, "sharding has been shut down")
+	}
+
+	if len(schemas) != len(drivers) {
+		return nil, fmt.Errorf("size %d can't be changed to %d", len(drivers), len(schemas))
+	}
+
+	builder := b.driverBuilder()
+	plan := &reloadPlan{}
+	nums := make(map[int]bool)
+
+	next := make([]*Driver, len(drivers))
+	for num, s := range schemas {
+		s = s.withPort(GetDialect(builder.name))
+		if drivers[num].key() == schemaKey(builder.name, builder.joiner(s), s) {
+			plan.reuse(drivers[num], s)
+			next[num] = drivers[num]
+			continue
+		}
+
+		d, err := builder.SetSchema(s).Build()
+		if err != nil {
+			plan.abort()
+			return nil, err
+		}
+
+		next[num] = d.label(b.id, Write, num)
+		nums[num] = true
+		plan.opened = append(plan.opened, d)
+		plan.closed = append(plan.closed, drivers[num])
+	}
+
+	if b.pingTimeout > 0 && len(nums) > 0 {
+		if err := pingShards(b.pingTimeout, next, nums); err != nil {
+			plan.abort()
+			return nil, err
+		}
+	}
+
+	plan.commit = func() {
+		x.mu.Lock()
+		defer x.mu.Unlock()
+
+		x.drivers = next
+		plan.apply()
+	}
+
+	return plan, nil
+}
+
+// 按新配置热加载，新增、删除或变更的唯一标识一并原子替换
+// 新配置中没有的唯一标识，等待进行中的调用结束后关闭
+func (x *Registry) Reload(profiles []*Profile) (*ReloadResult, error) {
+	b := &RegistryBuilder{}
+	b.
+		SetDsnJoiner(x.dsnJoiner).
+		SetShardingJoiner(x.shardingJoiner).
+		SetLazy(x.lazy).
+		SetPingTimeout(x.pingTimeout).
+		SetRetry(x.retry).
+		SetBreaker(x.breaker).
+		SetScanner(x.scanner)
+
+	for _, i := range x.interceptors {
+		b.AddInterceptor(i)
+	}
+
+	for _, p := range profiles {
+		if err := b.AddProfile(p); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(b.ids) == 0 {
+		return nil, errors.New("profiles can't be empty")
+	}
+
+	x.reloadMu.Lock()
+	defer x.reloadMu.Unlock()
+
+	x.mu.RLock()
+	if x.closed {
+		x.mu.RUnlock()
+		return nil, newKindError(ErrShutdown, "registry has been shut down")
+	}
+
+	drivers := make(map[string]*Drivers, len(x.drivers))
+	for id, d := range x.drivers {
+		drivers[id] = d
+	}
+
+	shardings := make(map[string]*Sharding, len(x.shardings))
+	for id, s := range x.shardings {
+		shardings[id] = s
+	}
+	x.mu.RUnlock()
+
+	r := &ReloadResult{}
+	added := &Registry{}
+	var plans []*reloadPlan
+
+	abort := func() {
+		for _, plan := range plans {
+			plan.abort()
+		}
+
+		added.Close()
+	}
+
+	for _, id := range b.ids {
+		profiles := b.profiles[id]
+		d, isDrivers := drivers[id]
+		s, isSharding := shardings[id]
+
+		var plan *reloadPlan
+		var err error
+
+		switch {
+		case isDrivers && !profiles[0].IsSharding():
+			builder := &DriversBuilder{}
+			builder.SetDsnJoiner(x.dsnJoiner).SetLazy(x.lazy).SetPingTimeout(x.pingTimeout).SetBreaker(x.breaker).SetScanner(x.scanner)
+			for _, i := range x.interceptors {
+				builder.AddInterceptor(i)
+			}
+
+			d.reloadMu.Lock()
+			defer d.reloadMu.Unlock()
+
+			if err = addProfiles(builder.AddProfile, profiles); err == nil {
+				plan, err = d.prepare(builder)
+			}
+		case isSharding && profiles[0].IsSharding():
+			builder := &ShardingBuilder{}
+			builder.SetDsnJoiner(x.dsnJoiner).SetShardingJoiner(x.shardingJoiner).SetLazy(x.lazy).SetPingTimeout(x.pingTimeout).SetRetry(x.retry).SetBreaker(x.breaker).SetScanner(x.scanner)
+			for _, i := range x.interceptors {
+				builder.AddInterceptor(i)
+			}
+
+			s.reloadMu.Lock()
+			defer s.reloadMu.Unlock()
+
+			if err = addProfiles(builder.AddProfile, profiles); err == nil {
+				plan, err = s.prepare(builder)
+			}
+		default:
+			err = b.build(added, id, profiles)
+		}
+
+		if err != nil {
+			abort()
+			return nil, fmt.Errorf(`id "%s": %w`, id, err)
+		}
+
+		if plan == nil {
+			if isDrivers || isSharding {
+				r.changed = append(r.changed, id)
+			} else {
+				r.added = append(r.added, id)
+			}
+			continue
+		}
+
+		plans = append(plans, plan)
+		r.opened += len(plan.opened)
+		r.kept += plan.kept
+
+		if len(plan.opened) > 0 || len(plan.closed) > 0 {
+			r.changed = append(r.changed, id)
+		}
+	}
+
+	var retired []*Driver
+	for _, d := range added.drivers {
+		r.opened += len(d.all())
+	}
+
+	for _, s := range added.shardings {
+		r.opened += len(s.GetDrivers())
+	}
+
+	x.mu.Lock()
+	for _, plan := range plans {
+		plan.commit()
+		retired = append(retired, plan.closed...)
+	}
+
+	for id, d := range drivers {
+		if _, ok := b.profiles[id]; ok && added.shardings[id] == nil {
+			continue
+		}
+
+		delete(x.drivers, id)
+		retired = append(retired, d.all()...)
+		if _, ok := b.profiles[id]; !ok {
+			r.removed = append(r.removed, id)
+		}
+	}
+
+	for id, s := range shardings {
+		if _, ok := b.profiles[id]; ok && added.drivers[id] == nil {
+			continue
+		}
+
+		delete(x.shardings, id)
+		retired = append(retired, s.GetDrivers()...)
+		if _, ok := b.profiles[id]; !ok {
+			r.removed = append(r.removed, id)
+		}
+	}
+
+	for id, d := range added.drivers {
+		if x.drivers == nil {
+			x.drivers = make(map[string]*Drivers)
+		}
+
+		x.drivers[id] = d
+	}
+
+	for id, s := range added.shardings {
+		if x.shardings == nil {
+			x.shardings = make(map[string]*Sharding)
+		}
+
+		x.shardings[id] = s
+	}
+	x.mu.Unlock()
+
+	r.closed = len(retired)
+	sort.Strings(r.removed)
+
+	retireDrivers(retired)
+	return r, nil
+}
+
+func addProfiles(add func(p *Profile) error, profiles []*Profile) error {
+	for _, p := range profiles {
+		if err := add(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 立即停用，后台等待进行中的调用结束再关闭，超过RetireTimeout时直接关闭
+func retireDrivers(drivers []*Driver) {
+	if len(drivers) == 0 {
+		return
+	}
+
+	for _, d := range drivers {
+		d.retire()
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), RetireTimeout)
+		defer cancel()
+
+		for _, d := range drivers {
+			_ = d.drain(ctx)
+			_ = d.Close()
+		}
+	}()
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type idempotentKey struct{}
+
+// 标记写操作幂等，允许自动重试，如：ExecContext(WithIdempotent(ctx), driver, "UPDATE ... SET x = ?", 1)
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func IsIdempotent(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	b, _ := ctx.Value(idempotentKey{}).(bool)
+	return b
+}
+
+// 重试策略，指数退避加随机抖动
+// 只自动重试读和显式标记幂等的写
+type Retry struct {
+	attempts   int                                  // 最多尝试次数，含首次
+	base       time.Duration                        // 首次退避时长
+	max        time.Duration                        // 最大退避时长
+	classifier func(driver *Driver, err error) bool // 是否可重试
+}
+
+// 按策略重试fn，pick返回每次尝试的Driver，tried为已尝试过的Driver
+func (x *Retry) Do(ctx context.Context, pick func(tried []*Driver) (*Driver, error), fn func(driver *Driver) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var tried []*Driver
+	for attempt := 1; ; attempt++ {
+		driver, err := pick(tried)
+		if err != nil {
+			return err
+		}
+
+		err = fn(driver)
+		if err == nil || attempt >= x.attempts || !x.classifier(driver, err) {
+			return err
+		}
+
+		tried = append(tried, driver)
+
+		timer := time.NewTimer(x.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// 第n次失败后的退避时长，[0, min(max, base * 2^(n-1))]内随机
+func (x *Retry) backoff(attempt int) time.Duration {
+	d := x.base
+	for i := 1; i < attempt && d < x.max; i++ {
+		d *= 2
+	}
+
+	if d > x.max {
+		d = x.max
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+func (x *Retry) GetAttempts() int {
+	return x.attempts
+}
+
+func (x *Retry) GetBase() time.Duration {
+	return x.base
+}
+
+func (x *Retry) GetMax() time.Duration {
+	return x.max
+}
+
+// 是否允许自动重试，读总是允许，写需标记幂等
+func retryable(ctx context.Context, method string) bool {
+	return method != MethodExec || IsIdempotent(ctx)
+}
+
+type RetryBuilder struct {
+	mu         sync.Mutex // ensures atomic writes; protects the following fields
+	attempts   int
+	base       time.Duration
+	max        time.Duration
+	classifier func(driver *Driver, err error) bool
+}
+
+func (x *RetryBuilder) Build() (*Retry, error) {
+	if x.attempts < 0 {
+		return nil, errors.New("attempts can't be less than 0")
+	}
+
+	if x.base < 0 {
+		return nil, errors.New("base can't be less than 0")
+	}
+
+	if x.max < 0 {
+		return nil, errors.New("max can't be less than 0")
+	}
+
+	attempts := x.attempts
+	if attempts == 0 {
+		attempts = RetryAttempts
+	}
+
+	base := x.base
+	if base == 0 {
+		base = RetryBase
+	}
+
+	max := x.max
+	if max == 0 {
+		max = RetryMax
+	}
+
+	if base > max {
+		return nil, errors.New("base can't be greater than max")
+	}
+
+	classifier := x.classifier
+	if classifier == nil {
+		classifier = func(driver *Driver, err error) bool {
+			if driver == nil {
+				return IsTransient(err)
+			}
+
+			return driver.GetDialect().IsTransient(err)
+		}
+	}
+
+	return &Retry{
+		attempts:   attempts,
+		base:       base,
+		max:        max,
+		classifier: classifier,
+	}, nil
+}
+
+// 最多尝试次数，含首次，缺省：RetryAttempts
+func (x *RetryBuilder) SetAttempts(n int) *RetryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.attempts = n
+	return x
+}
+
+// 首次退避时长，缺省：RetryBase
+func (x *RetryBuilder) SetBase(d time.Duration) *RetryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.base = d
+	return x
+}
+
+// 最大退避时长，缺省：RetryMax
+func (x *RetryBuilder) SetMax(d time.Duration) *RetryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.max = d
+	return x
+}
+
+// 按Driver分类错误，缺省：driver.GetDialect().IsTransient
+func (x *RetryBuilder) SetClassifier(f func(driver *Driver, err error) bool) *RetryBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.classifier = f
+	return x
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeMySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (x *fakeMySQLError) Error() string {
+	return x.Message
+}
+
+func TestRetry(t *testing.T) {
+	deadlock := &fakeMySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	setFakeResult("SELECT id FROM retry", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}, err: deadlock, fails: 2})
+	setFakeResult("UPDATE retry SET id = 1", &fakeResult{affected: 1, err: deadlock, fails: 1})
+
+	b := &RetryBuilder{}
+	retry, err := b.SetBase(time.Millisecond).SetMax(time.Millisecond).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := &DriverBuilder{}
+	d, err := db.SetName("fake").SetSchema(&Schema{host: "127.0.0.1", port: Port, database: "test", username: "root"}).SetRetry(retry).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts int
+	_ = d.AddInterceptor(InterceptorFunc(func(c *Call, next Handler) error {
+		attempts++
+		return next(c)
+	}))
+
+	got, err, _ := Find(d, "SELECT id FROM retry")
+	if err != nil || len(got) != 1 {
+		t.Errorf("got %v, %v; want 1 row, nil", got, err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("got %d attempts; want 3", attempts)
+	}
+
+	if _, err := Exec(d, "UPDATE retry SET id = 1"); !errors.Is(err, deadlock) {
+		t.Errorf("got %v; want %v", err, deadlock)
+	}
+
+	setFakeResult("UPDATE retry SET id = 1", &fakeResult{affected: 1, err: deadlock, fails: 1})
+	if _, err := ExecContext(WithIdempotent(context.Background()), d, "UPDATE retry SET id = 1"); err != nil {
+		t.Errorf("got %v; want nil", err)
+	}
+}
+
+func TestDriversDoRetry(t *testing.T) {
+	deadlock := &fakeMySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+
+	retry, err := (&RetryBuilder{}).SetBase(time.Millisecond).SetMax(time.Millisecond).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &DriversBuilder{}
+	_ = b.SetId("user")
+	_ = b.SetName("fake")
+	_ = b.AddReader(&Schema{host: "127.0.0.1", port: Port, database: "user", username: "root"})
+	_ = b.AddReader(&Schema{host: "127.0.0.2", port: Port, database: "user", username: "root"})
+	b.SetLazy(true).SetRetry(retry)
+
+	d, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var picked []*Driver
+	got := d.Do(context.Background(), Read, func(driver *Driver) error {
+		picked = append(picked, driver)
+		if len(picked) == 1 {
+			return deadlock
+		}
+
+		return nil
+	})
+
+	if got != nil {
+		t.Errorf("got %v; want nil", got)
+	}
+
+	if len(picked) != 2 || picked[0] == picked[1] {
+		t.Errorf("got %d attempts on %v; want 2 attempts on different readers", len(picked), picked)
+	}
+
+	picked = nil
+	got2 := d.Do(context.Background(), Write, func(driver *Driver) error {
+		picked = append(picked, driver)
+		return nil
+	})
+
+	if !errors.Is(got2, ErrNoDriver) || len(picked) != 0 {
+		t.Errorf("got %v after %d attempts; want ErrNoDriver", got2, len(picked))
+	}
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// 执行一次调用，经熔断器和拦截器链，Driver设置重试策略时，读和幂等的写自动重试
+// 查询结果空以外的错误，包装为*QueryError
+// fn可能被调用多次，每次调用前应重置上次尝试的结果
+func run(ctx context.Context, driver *Driver, method string, query string, args []interface{}, fn func(db *sql.DB, c *Call) error) error {
+	if driver == nil {
+		return ErrNilDriver
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	query, args, err := expandIn(driver.GetDialect(), query, args, GetEmptyIn(ctx))
+	if err != nil {
+		return wrapQueryError(driver, query, err)
+	}
+
+	if limit := driver.GetDialect().GetMaxPlaceholders(); len(args) > limit {
+		return wrapQueryError(driver, query, newKindError(ErrTooManyPlaceholders, "%d placeholders can't be greater than %d", len(args), limit))
+	}
+
+	attempt := func(driver *Driver) error {
+		breaker := driver.GetBreaker()
+
+		var turn uint64
+		if breaker != nil {
+			var ok bool
+			if turn, ok = breaker.allow(); !ok {
+				return ErrCircuitOpen
+			}
+		}
+
+		db, release, err := driver.acquire()
+		if err != nil {
+			if breaker != nil {
+				breaker.release(turn)
+			}
+
+			return err
+		}
+
+		defer release()
+
+		c := newCall(ctx, driver, method, query, args)
+		err = invoke(c, func(c *Call) error {
+			return fn(db, c)
+		})
+
+		if breaker != nil {
+			breaker.record(c.GetDuration(), c.GetErr())
+		}
+
+		return err
+	}
+
+	retry := driver.GetRetry()
+	if retry == nil || !retryable(ctx, method) {
+		err = attempt(driver)
+	} else {
+		err = retry.Do(ctx, func(tried []*Driver) (*Driver, error) {
+			return driver, nil
+		}, attempt)
+	}
+
+	return wrapQueryError(driver, query, err)
+}
+
+// 查询结果空以外的错误，包装为*QueryError
+func wrapQueryError(driver *Driver, query string, err error) error {
+	if err == nil || IsEmptyResult(err) {
+		return err
+	}
+
+	return &QueryError{
+		DriverId: driver.GetId(),
+		Role:     ModeName(driver.GetMode()),
+		Query:    query,
+		Err:      err,
+		dialect:  driver.GetDialect(),
+	}
+}
@@ -0,0 +1,26 @@
+package database
+
+import "strings"
+
+// 脱敏sql，字符串和数字替换为?，去掉注释，按MySQL语法切分
+func Sanitize(query string) string {
+	return sanitize(nil, query)
+}
+
+// 按方言脱敏sql
+func sanitize(d Dialect, query string) string {
+	var bucket strings.Builder
+
+	for _, t := range lexSql(d, query) {
+		switch t.kind {
+		case tokenString, tokenNumber:
+			bucket.WriteRune('?')
+		case tokenComment:
+			bucket.WriteRune(' ')
+		default:
+			bucket.WriteString(t.text)
+		}
+	}
+
+	return strings.TrimSpace(bucket.String())
+}
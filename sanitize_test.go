@@ -0,0 +1,17 @@
+package database
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	got := Sanitize("SELECT * FROM user WHERE name = 'it''s' AND id IN (1, 2.5, 0x1F) AND phone = ? -- comment")
+	want := "SELECT * FROM user WHERE name = ? AND id IN (?, ?, ?) AND phone = ?"
+	if got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	got2 := Sanitize("SELECT `user_1`.id, \"order\" FROM user_1 WHERE created_at::date = $1 /* c */")
+	want2 := "SELECT `user_1`.id, \"order\" FROM user_1 WHERE created_at::date = $1"
+	if got2 != want2 {
+		t.Errorf("got %q; want %q", got2, want2)
+	}
+}
@@ -0,0 +1,224 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 列类型，按DatabaseTypeName()归类
+const (
+	columnRaw     = iota // 不转换
+	columnString         // string
+	columnInt            // int64
+	columnUint           // uint64
+	columnFloat          // float64
+	columnDecimal        // string或*big.Rat
+	columnTime           // time.Time
+	columnBool           // bool
+	columnJson           // json.RawMessage
+)
+
+// 驱动返回的时间字符串格式，依次尝试
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+// TINYINT(1)转为bool需驱动在DatabaseTypeName()中带显示宽度（如：TINYINT(1)）或支持ColumnType.Length()
+// go-sql-driver/mysql两者均不提供，TINYINT(1)按整数返回
+func columnKind(t *sql.ColumnType) int {
+	name := strings.ToUpper(t.DatabaseTypeName())
+	unsigned := strings.HasPrefix(name, "UNSIGNED ")
+	name = strings.TrimPrefix(name, "UNSIGNED ")
+
+	width := int64(-1)
+	if i := strings.IndexByte(name, '('); i > 0 && strings.HasSuffix(name, ")") {
+		if n, err := strconv.ParseInt(name[i+1:len(name)-1], 10, 64); err == nil {
+			width = n
+		}
+
+		name = name[:i]
+	} else if n, ok := t.Length(); ok {
+		width = n
+	}
+
+	integer := columnInt
+	if unsigned {
+		integer = columnUint
+	}
+
+	switch name {
+	case "TINYINT":
+		if width == 1 {
+			return columnBool
+		}
+
+		return integer
+	case "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "INT2", "INT4", "INT8", "YEAR", "SERIAL", "BIGSERIAL", "SMALLSERIAL":
+		return integer
+	case "FLOAT", "DOUBLE", "REAL", "FLOAT4", "FLOAT8", "DOUBLE PRECISION":
+		return columnFloat
+	case "DECIMAL", "NUMERIC":
+		return columnDecimal
+	case "DATE", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return columnTime
+	case "BOOL", "BOOLEAN":
+		return columnBool
+	case "JSON", "JSONB":
+		return columnJson
+	case "CHAR", "VARCHAR", "TEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET", "TIME",
+		"BPCHAR", "NCHAR", "NVARCHAR", "CHARACTER", "CHARACTER VARYING", "CITEXT", "NAME", "UUID":
+		return columnString
+	default:
+		return columnRaw
+	}
+}
+
+// 按列类型转换驱动返回的值，NULL为nil，无法识别的值原样返回
+func (x *Scanner) convert(kind int, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	s, isText := columnText(v)
+
+	switch kind {
+	case columnString:
+		if isText {
+			return s, nil
+		}
+	case columnInt:
+		switch n := v.(type) {
+		case int64:
+			return n, nil
+		case uint64:
+			if n > math.MaxInt64 {
+				return nil, fmt.Errorf("value %d overflows int64", n)
+			}
+
+			return int64(n), nil
+		}
+
+		if isText {
+			return strconv.ParseInt(s, 10, 64)
+		}
+	case columnUint:
+		switch n := v.(type) {
+		case int64:
+			if n < 0 {
+				return nil, fmt.Errorf("value %d overflows uint64", n)
+			}
+
+			return uint64(n), nil
+		case uint64:
+			return n, nil
+		}
+
+		if isText {
+			return strconv.ParseUint(s, 10, 64)
+		}
+	case columnFloat:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case float32:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		}
+
+		if isText {
+			return strconv.ParseFloat(s, 64)
+		}
+	case columnDecimal:
+		return x.convertDecimal(v, s, isText)
+	case columnTime:
+		if t, ok := v.(time.Time); ok {
+			return t, nil
+		}
+
+		if isText {
+			return parseTime(s, x.GetLocation())
+		}
+	case columnBool:
+		switch n := v.(type) {
+		case bool:
+			return n, nil
+		case int64:
+			return n != 0, nil
+		}
+
+		if isText {
+			return strconv.ParseBool(s)
+		}
+	case columnJson:
+		if b, ok := v.([]byte); ok {
+			return json.RawMessage(b), nil
+		}
+
+		if isText {
+			return json.RawMessage(s), nil
+		}
+	}
+
+	return v, nil
+}
+
+func (x *Scanner) convertDecimal(v interface{}, s string, isText bool) (interface{}, error) {
+	if !isText {
+		switch n := v.(type) {
+		case int64:
+			s = strconv.FormatInt(n, 10)
+		case float64:
+			s = strconv.FormatFloat(n, 'f', -1, 64)
+		default:
+			return v, nil
+		}
+	}
+
+	if x.GetDecimal() == DecimalString {
+		return s, nil
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf(`invalid decimal "%s"`, s)
+	}
+
+	return r, nil
+}
+
+func columnText(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case []byte:
+		return string(s), true
+	case string:
+		return s, true
+	default:
+		return "", false
+	}
+}
+
+// MySQL的零值日期转为time.Time{}
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if strings.HasPrefix(s, "0000-00-00") {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(`invalid time "%s"`, s)
+}
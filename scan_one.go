@@ -0,0 +1,8 @@
+package database
+
+import "database/sql"
+
+// 按驱动返回的原值，多于一行时返回ErrMultipleRows
+func ScanOne(rows *sql.Rows) (map[string]interface{}, error) {
+	return (*Scanner)(nil).ScanOne(rows)
+}
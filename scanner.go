@@ -0,0 +1,363 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 按配置将rows转为map，nil时按驱动返回的原值
+type Scanner struct {
+	typed     bool           // 按rows.ColumnTypes()转换为Go类型
+	decimal   int            // DECIMAL的转换方式
+	location  *time.Location // 解析时间字符串的时区
+	duplicate int            // 列名重复时的处理方式
+}
+
+func (x *Scanner) Scan(rows *sql.Rows) ([]map[string]interface{}, error) {
+	c, err := x.prepare(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var r []map[string]interface{}
+	for rows.Next() {
+		pairs, err := c.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		r = append(r, pairs)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(r) == 0 {
+		return nil, NewEmptyResult()
+	}
+
+	return r, nil
+}
+
+func (x *Scanner) ScanFirst(rows *sql.Rows) (map[string]interface{}, error) {
+	c, err := x.prepare(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if rows.Next() {
+		return c.scan(rows)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, NewEmptyResult()
+}
+
+// 只取一行，多于一行时返回ErrMultipleRows
+func (x *Scanner) ScanOne(rows *sql.Rows) (map[string]interface{}, error) {
+	c, err := x.prepare(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, NewEmptyResult()
+	}
+
+	r, err := c.scan(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if rows.Next() {
+		return nil, ErrMultipleRows
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// 保留列的顺序，重复的列名按duplicate处理，DuplicateOverwrite时保留全部重复的列
+func (x *Scanner) ScanOrdered(rows *sql.Rows) ([]*OrderedRow, error) {
+	c, err := x.prepare(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var r []*OrderedRow
+	for rows.Next() {
+		values, err := c.values(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		r = append(r, &OrderedRow{columns: c.names, values: values})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(r) == 0 {
+		return nil, NewEmptyResult()
+	}
+
+	return r, nil
+}
+
+func (x *Scanner) GetTyped() bool {
+	return x != nil && x.typed
+}
+
+func (x *Scanner) GetDecimal() int {
+	if x == nil {
+		return DecimalString
+	}
+
+	return x.decimal
+}
+
+func (x *Scanner) GetLocation() *time.Location {
+	if x == nil {
+		return time.UTC
+	}
+
+	return x.location
+}
+
+func (x *Scanner) GetDuplicate() int {
+	if x == nil {
+		return DuplicateOverwrite
+	}
+
+	return x.duplicate
+}
+
+// 按duplicate处理重复的列名，database/sql不提供列所属的表，无法用"表名.列名"区分
+func (x *Scanner) resolve(columns []string) ([]string, error) {
+	mode := x.GetDuplicate()
+	if mode == DuplicateOverwrite {
+		return columns, nil
+	}
+
+	seen := make(map[string]int, len(columns))
+	for _, name := range columns {
+		seen[name]++
+	}
+
+	var r []string
+	counts := make(map[string]int, len(columns))
+	for i, name := range columns {
+		if seen[name] == 1 {
+			continue
+		}
+
+		if mode == DuplicateError {
+			return nil, fmt.Errorf(`column "%s" is duplicated`, name)
+		}
+
+		if r == nil {
+			r = append([]string{}, columns...)
+		}
+
+		counts[name]++
+		if counts[name] == 1 {
+			continue
+		}
+
+		n := counts[name]
+		for seen[fmt.Sprintf("%s_%d", name, n)] > 0 {
+			n++
+		}
+
+		r[i] = fmt.Sprintf("%s_%d", name, n)
+		seen[r[i]]++
+		counts[name] = n
+	}
+
+	if r == nil {
+		return columns, nil
+	}
+
+	return r, nil
+}
+
+// 读取列名和列类型，分配扫描缓冲区，rows的每行复用
+func (x *Scanner) prepare(rows *sql.Rows) (*scanColumns, error) {
+	if rows == nil {
+		return nil, errors.New("rows can't be nil")
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(columns)
+	if size == 0 {
+		return nil, errors.New("column size can't be equal than 0")
+	}
+
+	names, err := x.resolve(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &scanColumns{
+		scanner:    x,
+		names:      names,
+		attributes: make([]interface{}, size),
+	}
+
+	for i := range c.attributes {
+		c.attributes[i] = new(interface{})
+	}
+
+	if x.GetTyped() {
+		types, err := rows.ColumnTypes()
+		if err != nil {
+			return nil, err
+		}
+
+		c.kinds = make([]int, size)
+		for i, t := range types {
+			c.kinds[i] = columnKind(t)
+		}
+	}
+
+	return c, nil
+}
+
+type scanColumns struct {
+	scanner    *Scanner
+	names      []string      // 列名
+	kinds      []int         // 列类型，nil时不转换
+	attributes []interface{} // 扫描缓冲区
+}
+
+func (x *scanColumns) scan(rows *sql.Rows) (map[string]interface{}, error) {
+	values, err := x.values(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make(map[string]interface{}, len(x.names))
+	for i, name := range x.names {
+		r[name] = values[i]
+	}
+
+	return r, nil
+}
+
+// 按列的顺序
+func (x *scanColumns) values(rows *sql.Rows) ([]interface{}, error) {
+	if err := rows.Scan(x.attributes...); err != nil {
+		return nil, err
+	}
+
+	r := make([]interface{}, len(x.names))
+	for i := range x.names {
+		v := *(x.attributes[i].(*interface{}))
+		if x.kinds != nil {
+			var err error
+			if v, err = x.scanner.convert(x.kinds[i], v); err != nil {
+				return nil, fmt.Errorf(`column "%s": %w`, x.names[i], err)
+			}
+		}
+
+		r[i] = v
+	}
+
+	return r, nil
+}
+
+type ScannerBuilder struct {
+	mu        sync.Mutex // ensures atomic writes; protects the following fields
+	typed     bool
+	decimal   int
+	location  *time.Location
+	duplicate int
+}
+
+func (x *ScannerBuilder) Build() (*Scanner, error) {
+	decimal := x.decimal
+	if decimal == 0 {
+		decimal = DecimalString
+	}
+
+	if decimal != DecimalString && decimal != DecimalRat {
+		return nil, fmt.Errorf("decimal %d must be DecimalString or DecimalRat", decimal)
+	}
+
+	location := x.location
+	if location == nil {
+		location = time.UTC
+	}
+
+	duplicate := x.duplicate
+	if duplicate == 0 {
+		duplicate = DuplicateOverwrite
+	}
+
+	if duplicate != DuplicateOverwrite && duplicate != DuplicateError && duplicate != DuplicateRename {
+		return nil, fmt.Errorf("duplicate %d must be DuplicateOverwrite, DuplicateError or DuplicateRename", duplicate)
+	}
+
+	return &Scanner{
+		typed:     x.typed,
+		decimal:   decimal,
+		location:  location,
+		duplicate: duplicate,
+	}, nil
+}
+
+// 按rows.ColumnTypes()转换为string、int64、uint64、float64、time.Time、bool、json.RawMessage，缺省：false
+// 超出int64或uint64范围的整数返回错误；go-sql-driver/mysql不提供显示宽度，TINYINT(1)按int64返回
+func (x *ScannerBuilder) SetTyped(b bool) *ScannerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.typed = b
+	return x
+}
+
+// DECIMAL转为string或*big.Rat，缺省：DecimalString
+func (x *ScannerBuilder) SetDecimal(n int) *ScannerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.decimal = n
+	return x
+}
+
+// 驱动返回时间字符串（如MySQL未设parseTime）时的时区，缺省：time.UTC
+func (x *ScannerBuilder) SetLocation(loc *time.Location) *ScannerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.location = loc
+	return x
+}
+
+// 列名重复时的处理方式，缺省：DuplicateOverwrite
+func (x *ScannerBuilder) SetDuplicate(n int) *ScannerBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.duplicate = n
+	return x
+}
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newScannerDriver(t *testing.T, s *Scanner) *Driver {
+	b := &DriverBuilder{}
+	d, err := b.SetName("fake").SetSchema(&Schema{host: "127.0.0.1", port: Port, database: "test", username: "root"}).SetScanner(s).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func TestScanner(t *testing.T) {
+	setFakeResult("SELECT * FROM goods", &fakeResult{
+		columns: []string{"id", "name", "stock", "price", "rate", "created_at", "on_sale", "extra", "remark"},
+		types:   []string{"BIGINT", "VARCHAR", "UNSIGNED INT", "DECIMAL", "DOUBLE", "DATETIME", "TINYINT", "JSON", "TEXT"},
+		lengths: []int64{0, 0, 0, 0, 0, 0, 1},
+		rows: [][]driver.Value{
+			{[]byte("1"), []byte("pen"), []byte("20"), []byte("1.10"), []byte("0.5"), []byte("2024-01-02 03:04:05"), []byte("1"), []byte(`{"a":1}`), nil},
+		},
+	})
+
+	b := &ScannerBuilder{}
+	s, err := b.SetTyped(true).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newScannerDriver(t, s)
+
+	got, err := QueryFirst(d, "SELECT * FROM goods")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"id":         int64(1),
+		"name":       "pen",
+		"stock":      uint64(20),
+		"price":      "1.10",
+		"rate":       0.5,
+		"created_at": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"on_sale":    true,
+		"remark":     nil,
+	}
+
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: got %#v; want %#v", k, got[k], v)
+		}
+	}
+
+	if extra, ok := got["extra"].(json.RawMessage); !ok || string(extra) != `{"a":1}` {
+		t.Errorf("extra: got %#v; want json.RawMessage", got["extra"])
+	}
+
+	s2, err := b.SetDecimal(DecimalRat).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d = newScannerDriver(t, s2)
+
+	got2, err := QueryFirst(d, "SELECT * FROM goods")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r, ok := got2["price"].(*big.Rat); !ok || r.Cmp(big.NewRat(11, 10)) != 0 {
+		t.Errorf("price: got %#v; want 11/10", got2["price"])
+	}
+}
+
+func TestScannerConvert(t *testing.T) {
+	setFakeResult("SELECT * FROM flags", &fakeResult{
+		columns: []string{"a", "b"},
+		types:   []string{"TINYINT(1)", "TINYINT"},
+		rows:    [][]driver.Value{{int64(1), int64(1)}},
+	})
+
+	s, err := (&ScannerBuilder{}).SetTyped(true).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newScannerDriver(t, s)
+	got, err := QueryFirst(d, "SELECT * FROM flags")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got["a"] != true || got["b"] != int64(1) {
+		t.Errorf("got %#v, %#v; want true, int64(1)", got["a"], got["b"])
+	}
+
+	setFakeResult("SELECT * FROM overflow", &fakeResult{
+		columns: []string{"n"},
+		types:   []string{"BIGINT"},
+		rows:    [][]driver.Value{{uint64(math.MaxUint64)}},
+	})
+
+	want := "value 18446744073709551615 overflows int64"
+	if _, err := QueryFirst(d, "SELECT * FROM overflow"); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got %v; want %q", err, want)
+	}
+}
+
+func TestScannerDuplicate(t *testing.T) {
+	setFakeResult("SELECT u.id, o.id, o.id_2 FROM user u JOIN orders o", &fakeResult{
+		columns: []string{"id", "id", "id_2", "id"},
+		rows:    [][]driver.Value{{int64(1), int64(2), int64(3), int64(4)}},
+	})
+
+	b := &ScannerBuilder{}
+	s, err := b.SetDuplicate(DuplicateError).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newScannerDriver(t, s)
+	want := `column "id" is duplicated`
+	if _, err := QueryFirst(d, "SELECT u.id, o.id, o.id_2 FROM user u JOIN orders o"); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got %v; want %q", err, want)
+	}
+
+	s2, err := b.SetDuplicate(DuplicateRename).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d = newScannerDriver(t, s2)
+	got, err := QueryFirst(d, "SELECT u.id, o.id, o.id_2 FROM user u JOIN orders o")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want2 := map[string]interface{}{"id": int64(1), "id_3": int64(2), "id_2": int64(3), "id_4": int64(4)}
+	for k, v := range want2 {
+		if got[k] != v {
+			t.Errorf("%s: got %v; want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestScanOrdered(t *testing.T) {
+	setFakeResult("SELECT name, id FROM user", &fakeResult{columns: []string{"name", "id"}, rows: [][]driver.Value{{"foo", int64(1)}}})
+
+	rows, err := newFakeDriver().GetDb().Query("SELECT name, id FROM user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	got, err := ScanOrdered(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || strings.Join(got[0].GetColumns(), ",") != "name,id" || got[0].GetValues()[1] != int64(1) {
+		t.Errorf("got %v; want [name id] [foo 1]", got)
+	}
+}
+
+func TestScannerAggregate(t *testing.T) {
+	setFakeResult("SELECT SUM(price) AS 'aggregate' FROM goods", &fakeResult{columns: []string{AggregateAlias}, types: []string{"DECIMAL"}, rows: [][]driver.Value{{[]byte("15.00")}}})
+	setFakeResult("SELECT AVG(price) AS 'aggregate' FROM goods", &fakeResult{columns: []string{AggregateAlias}, types: []string{"DECIMAL"}, rows: [][]driver.Value{{[]byte("2.50")}}})
+	setFakeResult("SELECT SUM(stock) AS 'aggregate' FROM goods", &fakeResult{columns: []string{AggregateAlias}, types: []string{"UNSIGNED BIGINT"}, rows: [][]driver.Value{{uint64(7)}}})
+	setFakeResult("SELECT MAX(stock) AS 'aggregate' FROM goods", &fakeResult{columns: []string{AggregateAlias}, types: []string{"UNSIGNED BIGINT"}, rows: [][]driver.Value{{uint64(math.MaxUint64)}}})
+
+	for _, decimal := range []int{DecimalString, DecimalRat} {
+		s, err := (&ScannerBuilder{}).SetTyped(true).SetDecimal(decimal).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d := newScannerDriver(t, s)
+
+		got, err, _ := AggregateInt(d, "SELECT SUM(price) AS 'aggregate' FROM goods")
+		if err != nil || got != 15 {
+			t.Errorf("decimal %d got %d, %v; want 15, nil", decimal, got, err)
+		}
+
+		got2, err, _ := AggregateFloat(d, "SELECT AVG(price) AS 'aggregate' FROM goods")
+		if err != nil || got2 != 2.5 {
+			t.Errorf("decimal %d got %v, %v; want 2.5, nil", decimal, got2, err)
+		}
+
+		if _, err := QueryAggregateInt(d, "SELECT AVG(price) AS 'aggregate' FROM goods"); err == nil {
+			t.Errorf("decimal %d got nil; want error for 2.5", decimal)
+		}
+
+		got3, err := QueryAggregateInt(d, "SELECT SUM(stock) AS 'aggregate' FROM goods")
+		if err != nil || got3 != 7 {
+			t.Errorf("got %d, %v; want 7, nil", got3, err)
+		}
+
+		got4, err := QueryAggregateFloat(d, "SELECT SUM(stock) AS 'aggregate' FROM goods")
+		if err != nil || got4 != 7 {
+			t.Errorf("got %v, %v; want 7, nil", got4, err)
+		}
+
+		if _, err := QueryAggregateInt(d, "SELECT MAX(stock) AS 'aggregate' FROM goods"); err == nil {
+			t.Errorf("got nil; want overflow error")
+		}
+	}
+}
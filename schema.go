@@ -9,39 +9,42 @@ import (
 )
 
 type Schema struct {
-	mu          sync.Mutex    // ensures atomic writes; protects the following fields
-	proto       string        // 协议，如：tcp，缺省：define.Proto
-	host        string        // 域名或Ip
-	port        int           // 端口，如：3306，缺省：define.Port
-	database    string        // 数据库名
-	username    string        // 用户名
-	password    string        // 密码
-	charset     string        // 缺省：define.Charset
-	collation   string        // 缺省：define.Collation
-	timeout     string        // 系统默认：90s
-	maxOpen     int           // 最大连接数，缺省：0，不设置，无限制
-	maxIdle     int           // 最大空闲连接数，缺省：0，不设置，默认2
-	maxLifetime time.Duration // 连接最大生命周期，缺省：0，不设置，永不过期
-	dsn         string        // data source name
+	mu             sync.Mutex            // ensures atomic writes; protects the following fields
+	proto          string                // 协议，如：tcp，缺省：define.Proto
+	host           string                // 域名或Ip
+	port           int                   // 端口，如：3306，缺省：0，GetPort返回define.Port，Driver按方言的缺省端口
+	database       string                // 数据库名
+	username       string                // 用户名
+	password       string                // 密码
+	charset        string                // 缺省：define.Charset
+	collation      string                // 缺省：define.Collation
+	timeout        string                // 系统默认：90s
+	maxOpen        int                   // 最大连接数，缺省：0，不设置，无限制
+	maxIdle        int                   // 最大空闲连接数，缺省：0，不设置，默认2
+	maxLifetime    time.Duration         // 连接最大生命周期，缺省：0，不设置，永不过期
+	dsn            string                // data source name
+	slowThreshold  time.Duration         // 慢查询阈值，缺省：0，按角色的缺省阈值
+	slowThresholds map[int]time.Duration // 角色 => 慢查询阈值，优先于slowThreshold
 }
 
 func (x *Schema) ToString() string {
-	return fmt.Sprintf("proto:       %v\n"+
-		"host:        %v\n"+
-		"port:        %v\n"+
-		"database:    %v\n"+
-		"username:    %v\n"+
-		"password:    %v\n"+
-		"charset:     %v\n"+
-		"collation:   %v\n"+
-		"timeout:     %v\n"+
-		"maxOpen:     %v\n"+
-		"maxIdle:     %v\n"+
-		"maxLifetime: %v\n"+
-		"dsn:         %v\n",
+	return fmt.Sprintf("proto:         %v\n"+
+		"host:          %v\n"+
+		"port:          %v\n"+
+		"database:      %v\n"+
+		"username:      %v\n"+
+		"password:      %v\n"+
+		"charset:       %v\n"+
+		"collation:     %v\n"+
+		"timeout:       %v\n"+
+		"maxOpen:       %v\n"+
+		"maxIdle:       %v\n"+
+		"maxLifetime:   %v\n"+
+		"dsn:           %v\n"+
+		"slowThreshold: %v\n",
 		x.GetProto(), x.GetHost(), x.GetPort(), x.GetDatabase(), x.GetUsername(), x.GetPassword(),
 		x.GetCharset(), x.GetCollation(), x.GetTimeout(),
-		x.GetMaxOpen(), x.GetMaxIdle(), x.GetMaxLifetime(), x.GetDsn(),
+		x.GetMaxOpen(), x.GetMaxIdle(), x.GetMaxLifetime(), x.GetDsn(), x.GetSlowThreshold(),
 	)
 }
 
@@ -53,7 +56,17 @@ func (x *Schema) GetHost() string {
 	return x.host
 }
 
+// 未配置时返回define.Port，Driver使用的Schema已按方言补齐
 func (x *Schema) GetPort() int {
+	return x.portOr(Port)
+}
+
+// 未配置时返回def
+func (x *Schema) portOr(def int) int {
+	if x.port == 0 {
+		return def
+	}
+
 	return x.port
 }
 
@@ -97,21 +110,68 @@ func (x *Schema) GetDsn() string {
 	return x.dsn
 }
 
+func (x *Schema) GetSlowThreshold() time.Duration {
+	return x.slowThreshold
+}
+
+// 未配置端口时，返回按方言缺省端口的副本
+func (x *Schema) withPort(d Dialect) *Schema {
+	if x == nil || x.port != 0 || d.GetPort() == 0 {
+		return x
+	}
+
+	r := x.clone()
+	r.port = d.GetPort()
+	return r
+}
+
+// 副本，slowThresholds只读，共用
+func (x *Schema) clone() *Schema {
+	return &Schema{
+		proto:          x.proto,
+		host:           x.host,
+		port:           x.port,
+		database:       x.database,
+		username:       x.username,
+		password:       x.password,
+		charset:        x.charset,
+		collation:      x.collation,
+		timeout:        x.timeout,
+		maxOpen:        x.maxOpen,
+		maxIdle:        x.maxIdle,
+		maxLifetime:    x.maxLifetime,
+		dsn:            x.dsn,
+		slowThreshold:  x.slowThreshold,
+		slowThresholds: x.slowThresholds,
+	}
+}
+
+// 按角色的慢查询阈值，未配置时取GetSlowThreshold
+func (x *Schema) GetRoleSlowThreshold(mode int) time.Duration {
+	if d := x.slowThresholds[mode]; d > 0 {
+		return d
+	}
+
+	return x.slowThreshold
+}
+
 type SchemaBuilder struct {
-	mu          sync.Mutex // ensures atomic writes; protects the following fields
-	proto       string
-	host        string
-	port        int
-	database    string
-	username    string
-	password    string
-	charset     string
-	collation   string
-	timeout     string
-	maxOpen     int
-	maxIdle     int
-	maxLifetime time.Duration
-	dsn         string
+	mu             sync.Mutex // ensures atomic writes; protects the following fields
+	proto          string
+	host           string
+	port           int
+	database       string
+	username       string
+	password       string
+	charset        string
+	collation      string
+	timeout        string
+	maxOpen        int
+	maxIdle        int
+	maxLifetime    time.Duration
+	dsn            string
+	slowThreshold  time.Duration
+	slowThresholds map[int]time.Duration
 }
 
 func (x *SchemaBuilder) Build() (*Schema, error) {
@@ -143,16 +203,20 @@ func (x *SchemaBuilder) Build() (*Schema, error) {
 		return nil, errors.New("max lifetime can't be less than 0")
 	}
 
+	if x.slowThreshold < 0 {
+		return nil, errors.New("slow threshold can't be less than 0")
+	}
+
+	slowThresholds := make(map[int]time.Duration, len(x.slowThresholds))
+	for mode, d := range x.slowThresholds {
+		slowThresholds[mode] = d
+	}
+
 	proto := x.proto
 	if proto == "" {
 		proto = Proto
 	}
 
-	port := x.port
-	if port == 0 {
-		port = Port
-	}
-
 	charset := x.charset
 	if charset == "" {
 		charset = Charset
@@ -164,19 +228,21 @@ func (x *SchemaBuilder) Build() (*Schema, error) {
 	}
 
 	return &Schema{
-		proto:       proto,
-		host:        x.host,
-		port:        port,
-		database:    x.database,
-		username:    x.username,
-		password:    x.password,
-		charset:     charset,
-		collation:   collation,
-		timeout:     x.timeout,
-		maxOpen:     x.maxOpen,
-		maxIdle:     x.maxIdle,
-		maxLifetime: x.maxLifetime,
-		dsn:         x.dsn,
+		proto:          proto,
+		host:           x.host,
+		port:           x.port,
+		database:       x.database,
+		username:       x.username,
+		password:       x.password,
+		charset:        charset,
+		collation:      collation,
+		timeout:        x.timeout,
+		maxOpen:        x.maxOpen,
+		maxIdle:        x.maxIdle,
+		maxLifetime:    x.maxLifetime,
+		dsn:            x.dsn,
+		slowThreshold:  x.slowThreshold,
+		slowThresholds: slowThresholds,
 	}, nil
 }
 
@@ -299,3 +365,32 @@ func (x *SchemaBuilder) SetDsn(s string) *SchemaBuilder {
 	x.dsn = s
 	return x
 }
+
+func (x *SchemaBuilder) SetSlowThreshold(d time.Duration) *SchemaBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.slowThreshold = d
+	return x
+}
+
+// 按角色设置慢查询阈值，mode：Write、Read、Backup，优先于SetSlowThreshold
+func (x *SchemaBuilder) SetRoleSlowThreshold(mode int, d time.Duration) error {
+	if err := CheckMode(mode); err != nil {
+		return err
+	}
+
+	if d < 0 {
+		return errors.New("slow threshold can't be less than 0")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.slowThresholds == nil {
+		x.slowThresholds = make(map[int]time.Duration)
+	}
+
+	x.slowThresholds[mode] = d
+	return nil
+}
@@ -17,9 +17,13 @@ func NewSchema(p *Profile) (*Schema, error) {
 	timeout := p.GetTimeout()
 	dsn := p.GetDsn()
 	port := p.GetPort()
+	if port == 0 {
+		port = GetDialect(p.GetDriver()).GetPort()
+	}
 	maxOpen := p.GetMaxOpen()
 	maxIdle := p.GetMaxIdle()
 	maxLifetime := p.GetMaxLifetime()
+	slowThreshold := p.GetSlowThreshold()
 
 	builder := &SchemaBuilder{}
 	builder.
@@ -35,7 +39,14 @@ func NewSchema(p *Profile) (*Schema, error) {
 		SetPort(port).
 		SetMaxOpen(maxOpen).
 		SetMaxIdle(maxIdle).
-		SetMaxLifetime(maxLifetime)
+		SetMaxLifetime(maxLifetime).
+		SetSlowThreshold(slowThreshold)
+
+	for mode, d := range p.GetSlowThresholds() {
+		if err := builder.SetRoleSlowThreshold(mode, d); err != nil {
+			return nil, err
+		}
+	}
 
 	return builder.Build()
 }
@@ -3,20 +3,33 @@ package database
 import (
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Sharding struct {
-	id      string    // 唯一标识
-	size    int       // 分库数
-	drivers []*Driver // 主库列表，分库时，不支持从库和备库
+	mu       sync.RWMutex // ensures atomic reloads; protects the following list
+	reloadMu sync.Mutex   // ensures serial reloads & shutdown
+	id       string       // 唯一标识
+	size     int          // 分库数
+	drivers  []*Driver    // 主库列表，分库时，不支持从库和备库
+	closed   bool         // 已关闭，不再分配Driver
 }
 
 // 取第n个库
 func (x *Sharding) GetDriver(num int) (*Driver, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if x.closed {
+		return nil, newKindError(ErrShutdown, "sharding has been shut down")
+	}
+
 	if x.drivers == nil {
-		return nil, errors.New("drivers can't be nil")
+		return nil, newKindError(ErrNoDriver, "drivers can't be nil")
 	}
 
 	if x.size <= 0 {
@@ -24,16 +37,64 @@ func (x *Sharding) GetDriver(num int) (*Driver, error) {
 	}
 
 	if num < 0 {
-		return nil, fmt.Errorf("num %d can't be less than 0", num)
+		return nil, newKindError(ErrShardOutOfRange, "num %d can't be less than 0", num)
 	}
 
 	if num >= x.size {
-		return nil, fmt.Errorf("num %d can't be greater or equal than size %d", num, x.size)
+		return nil, newKindError(ErrShardOutOfRange, "num %d can't be greater or equal than size %d", num, x.size)
 	}
 
 	return x.drivers[num], nil
 }
 
+// 按分库键取库，整数按取模，字符串按crc32取模
+func (x *Sharding) GetDriverByKey(key interface{}) (*Driver, error) {
+	num, err := shardNum(key, x.GetSize())
+	if err != nil {
+		return nil, err
+	}
+
+	return x.GetDriver(num)
+}
+
+func shardNum(key interface{}, size int) (int, error) {
+	if size <= 0 {
+		return 0, fmt.Errorf("size %d can't be less or equal than 0", size)
+	}
+
+	var n uint64
+	switch k := key.(type) {
+	case int:
+		n = absInt(int64(k))
+	case int32:
+		n = absInt(int64(k))
+	case int64:
+		n = absInt(k)
+	case uint:
+		n = uint64(k)
+	case uint32:
+		n = uint64(k)
+	case uint64:
+		n = k
+	case string:
+		n = uint64(crc32.ChecksumIEEE([]byte(k)))
+	case []byte:
+		n = uint64(crc32.ChecksumIEEE(k))
+	default:
+		return 0, fmt.Errorf("unsupported key type %T", key)
+	}
+
+	return int(n % uint64(size)), nil
+}
+
+func absInt(n int64) uint64 {
+	if n < 0 {
+		return uint64(-n)
+	}
+
+	return uint64(n)
+}
+
 func (x *Sharding) GetId() string {
 	return x.id
 }
@@ -43,11 +104,17 @@ func (x *Sharding) GetSize() int {
 }
 
 func (x *Sharding) GetDrivers() []*Driver {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
 	return x.drivers
 }
 
 // 关闭全部sql.DB
 func (x *Sharding) Close() []error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
 	var r []error
 
 	if x.drivers != nil {
@@ -68,9 +135,49 @@ type ShardingBuilder struct {
 	dsnJoiner      func(s *Schema) string                // dsn拼接函数
 	shardingJoiner func(database string, num int) string // sharding拼接函数
 	schemas        map[int]*Schema                       // 第n个库 => 库
+	lazy           bool                                  // 懒加载，首次使用才调用sql.Open，缺省：false
+	pingTimeout    time.Duration                         // Build时并发校验全部连接的超时时间，缺省：0-不校验
+	interceptors   []Interceptor                         // 拦截器，用于全部Driver
+	retry          *Retry                                // 重试策略，用于全部Driver，分库时在同一Driver上重试
+	breaker        *BreakerBuilder                       // 熔断器，每个Driver一个
+	scanner        *Scanner                              // 查询结果转换，用于全部Driver
 }
 
 func (x *ShardingBuilder) Build() (*Sharding, error) {
+	schemas, err := x.shardSchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	builder := x.driverBuilder()
+
+	var drivers []*Driver
+	for num, s := range schemas {
+		d, err := builder.SetSchema(s).Build()
+		if err != nil {
+			closeDrivers(drivers)
+			return nil, err
+		}
+
+		drivers = append(drivers, d.label(x.id, Write, num))
+	}
+
+	if x.pingTimeout > 0 {
+		if err := pingShards(x.pingTimeout, drivers, nil); err != nil {
+			closeDrivers(drivers)
+			return nil, err
+		}
+	}
+
+	return &Sharding{
+		id:      x.id,
+		size:    len(drivers),
+		drivers: drivers,
+	}, nil
+}
+
+// 校验配置，按第n个库拼接库名，返回各库配置的副本，同一builder可多次Build或Reload
+func (x *ShardingBuilder) shardSchemas() ([]*Schema, error) {
 	if x.id == "" {
 		return nil, errors.New("id can't be empty")
 	}
@@ -102,9 +209,8 @@ func (x *ShardingBuilder) Build() (*Sharding, error) {
 		}
 	}
 
-	dsnJoiner := x.dsnJoiner
-	if dsnJoiner == nil {
-		dsnJoiner = DsnJoiner
+	if x.lazy && x.pingTimeout > 0 {
+		return nil, errors.New("lazy and ping timeout can't be both set")
 	}
 
 	shardingJoiner := x.shardingJoiner
@@ -112,27 +218,47 @@ func (x *ShardingBuilder) Build() (*Sharding, error) {
 		shardingJoiner = ShardingJoiner
 	}
 
-	builder := DriverBuilder{}
-	builder.SetName(x.name).SetJoiner(dsnJoiner)
-
-	var drivers []*Driver
+	r := make([]*Schema, size)
 	for num := 0; num < size; num++ {
-		s := x.schemas[num]
+		s := x.schemas[num].clone()
 		s.database = shardingJoiner(s.GetDatabase(), num)
+		r[num] = s
+	}
 
-		d, err := builder.SetSchema(s).Build()
-		if err != nil {
-			return nil, err
-		}
+	return r, nil
+}
 
-		drivers = append(drivers, d)
+func (x *ShardingBuilder) driverBuilder() *DriverBuilder {
+	dsnJoiner := x.dsnJoiner
+	if dsnJoiner == nil {
+		dsnJoiner = GetDialect(x.name).Dsn
 	}
 
-	return &Sharding{
-		id:      x.id,
-		size:    len(drivers),
-		drivers: drivers,
-	}, nil
+	builder := &DriverBuilder{}
+	builder.SetName(x.name).SetJoiner(dsnJoiner).SetLazy(x.lazy).SetRetry(x.retry).SetBreaker(x.breaker).SetScanner(x.scanner)
+	for _, i := range x.interceptors {
+		builder.AddInterceptor(i)
+	}
+
+	return builder
+}
+
+// 并发校验分库连接，nums非空时，仅校验其中的库
+func pingShards(timeout time.Duration, drivers []*Driver, nums map[int]bool) error {
+	labels := make(map[string][]*Driver, len(drivers))
+	for num, d := range drivers {
+		if nums == nil || nums[num] {
+			labels[shardLabel(num, len(drivers))] = []*Driver{d}
+		}
+	}
+
+	return pingDrivers(timeout, labels)
+}
+
+// 分库标签，按分库数补零，如：shard 02，按字符串排序时与库序一致
+func shardLabel(num int, size int) string {
+	width := len(strconv.Itoa(size - 1))
+	return fmt.Sprintf("shard %0*d", width, num)
 }
 
 func (x *ShardingBuilder) SetId(s string) error {
@@ -191,6 +317,57 @@ func (x *ShardingBuilder) SetShardingJoiner(f func(database string, num int) str
 	return x
 }
 
+func (x *ShardingBuilder) SetLazy(b bool) *ShardingBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.lazy = b
+	return x
+}
+
+func (x *ShardingBuilder) SetPingTimeout(d time.Duration) *ShardingBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.pingTimeout = d
+	return x
+}
+
+func (x *ShardingBuilder) SetRetry(r *Retry) *ShardingBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.retry = r
+	return x
+}
+
+func (x *ShardingBuilder) SetBreaker(b *BreakerBuilder) *ShardingBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.breaker = b
+	return x
+}
+
+func (x *ShardingBuilder) SetScanner(s *Scanner) *ShardingBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.scanner = s
+	return x
+}
+
+func (x *ShardingBuilder) AddInterceptor(i Interceptor) *ShardingBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if i != nil {
+		x.interceptors = append(x.interceptors, i)
+	}
+
+	return x
+}
+
 func (x *ShardingBuilder) SetSchema(num int, s *Schema) error {
 	if num < 0 {
 		return errors.New("num can't be less than 0")
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// 停止分配Driver，等待使用中的连接归零或ctx到期后，关闭全部sql.DB
+func (x *Drivers) Shutdown(ctx context.Context) error {
+	x.reloadMu.Lock()
+	x.mu.Lock()
+	x.closed = true
+	labels := map[string][]*Driver{ModeName(Write): x.writers, ModeName(Read): x.readers, ModeName(Backup): x.backups}
+	x.mu.Unlock()
+	x.reloadMu.Unlock()
+
+	return shutdownDrivers(ctx, []string{ModeName(Write), ModeName(Read), ModeName(Backup)}, labels)
+}
+
+// 停止分配Driver，等待使用中的连接归零或ctx到期后，关闭全部sql.DB
+func (x *Sharding) Shutdown(ctx context.Context) error {
+	x.reloadMu.Lock()
+	x.mu.Lock()
+	x.closed = true
+	drivers := x.drivers
+	x.mu.Unlock()
+	x.reloadMu.Unlock()
+
+	var names []string
+	labels := make(map[string][]*Driver, len(drivers))
+	for num, d := range drivers {
+		name := shardLabel(num, len(drivers))
+		names = append(names, name)
+		labels[name] = []*Driver{d}
+	}
+
+	return shutdownDrivers(ctx, names, labels)
+}
+
+// 停止分配Driver，等待全部主从库和分库使用中的连接归零或ctx到期后，关闭全部sql.DB
+// 持锁取快照后释放，等待期间不阻塞Get等调用，进行中的热加载结束后才开始关闭
+func (x *Registry) Shutdown(ctx context.Context) error {
+	x.reloadMu.Lock()
+	x.mu.Lock()
+	x.closed = true
+
+	ids := make([]string, 0, len(x.drivers))
+	drivers := make(map[string]*Drivers, len(x.drivers))
+	for id, d := range x.drivers {
+		ids = append(ids, id)
+		drivers[id] = d
+	}
+
+	shardingIds := make([]string, 0, len(x.shardings))
+	shardings := make(map[string]*Sharding, len(x.shardings))
+	for id, s := range x.shardings {
+		shardingIds = append(shardingIds, id)
+		shardings[id] = s
+	}
+	x.mu.Unlock()
+	x.reloadMu.Unlock()
+
+	sort.Strings(ids)
+	sort.Strings(shardingIds)
+
+	var r []error
+
+	for _, id := range ids {
+		if err := drivers[id].Shutdown(ctx); err != nil {
+			r = append(r, fmt.Errorf(`id "%s": %w`, id, err))
+		}
+	}
+
+	for _, id := range shardingIds {
+		if err := shardings[id].Shutdown(ctx); err != nil {
+			r = append(r, fmt.Errorf(`id "%s": %w`, id, err))
+		}
+	}
+
+	return errors.Join(r...)
+}
+
+// 按names顺序等待并关闭，汇总失败的Driver
+func shutdownDrivers(ctx context.Context, names []string, labels map[string][]*Driver) error {
+	var r []error
+
+	for _, name := range names {
+		for _, d := range labels[name] {
+			d.retire()
+		}
+	}
+
+	for _, name := range names {
+		for _, d := range labels[name] {
+			if err := d.drain(ctx); err != nil {
+				r = append(r, driverError("drain", name, d, err))
+			}
+		}
+	}
+
+	for _, name := range names {
+		for _, d := range labels[name] {
+			if err := d.Close(); err != nil {
+				r = append(r, driverError("close", name, d, err))
+			}
+		}
+	}
+
+	return errors.Join(r...)
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 慢查询日志拦截器，超过阈值时经slog输出
+// 阈值：Driver按角色配置的阈值（如：read_slow_threshold）优先，其次slow_threshold，最后按角色的缺省阈值
+type SlowLog struct {
+	logger     *slog.Logger
+	level      slog.Level
+	thresholds map[int]time.Duration // 角色 => 缺省阈值，0-未经Drivers或Sharding构建的Driver
+}
+
+func (x *SlowLog) Intercept(c *Call, next Handler) error {
+	err := next(c)
+
+	threshold := x.GetThreshold(c.GetDriver())
+	if threshold <= 0 || c.GetDuration() < threshold {
+		return err
+	}
+
+	x.log(c, threshold)
+	return err
+}
+
+// Driver的慢查询阈值，0-不记录
+func (x *SlowLog) GetThreshold(driver *Driver) time.Duration {
+	if s := driver.GetSchema(); s != nil {
+		if d := s.GetRoleSlowThreshold(driver.GetMode()); d > 0 {
+			return d
+		}
+	}
+
+	return x.thresholds[driver.GetMode()]
+}
+
+func (x *SlowLog) log(c *Call, threshold time.Duration) {
+	driver := c.GetDriver()
+
+	attrs := []slog.Attr{
+		slog.String("query", sanitize(driver.GetDialect(), c.GetQuery())),
+		slog.String("args", RedactArgs(c.GetArgs())),
+		slog.Duration("duration", c.GetDuration()),
+		slog.Duration("threshold", threshold),
+		slog.Int64("rows", c.GetRows()),
+		slog.String("method", c.GetMethod()),
+	}
+
+	if s := driver.GetSchema(); s != nil {
+		attrs = append(attrs, slog.String("host", s.GetHost()), slog.String("database", s.GetDatabase()))
+	}
+
+	if id := driver.GetId(); id != "" {
+		attrs = append(attrs, slog.String("id", id))
+	}
+
+	if role := ModeName(driver.GetMode()); role != "" {
+		attrs = append(attrs, slog.String("role", role))
+	}
+
+	if num := driver.GetNum(); num >= 0 {
+		attrs = append(attrs, slog.Int("shard", num))
+	}
+
+	if err := c.GetErr(); err != nil && !IsEmptyResult(err) {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	x.logger.LogAttrs(c.GetContext(), x.level, "slow query", attrs...)
+}
+
+// 参数摘要，只输出类型和长度，不输出值，如：[int64, string(11), nil]
+func RedactArgs(args []interface{}) string {
+	r := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			r[i] = "nil"
+		case string:
+			r[i] = fmt.Sprintf("string(%d)", len(v))
+		case []byte:
+			r[i] = fmt.Sprintf("[]byte(%d)", len(v))
+		default:
+			r[i] = fmt.Sprintf("%T", v)
+		}
+	}
+
+	return "[" + strings.Join(r, ", ") + "]"
+}
+
+type SlowLogBuilder struct {
+	mu         sync.Mutex // ensures atomic writes; protects the following fields
+	logger     *slog.Logger
+	level      slog.Leveler
+	thresholds map[int]time.Duration
+}
+
+func (x *SlowLogBuilder) Build() (*SlowLog, error) {
+	logger := x.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var level slog.Leveler = slog.LevelWarn
+	if x.level != nil {
+		level = x.level
+	}
+
+	thresholds := make(map[int]time.Duration, len(x.thresholds))
+	for mode, d := range x.thresholds {
+		thresholds[mode] = d
+	}
+
+	return &SlowLog{
+		logger:     logger,
+		level:      level.Level(),
+		thresholds: thresholds,
+	}, nil
+}
+
+func (x *SlowLogBuilder) SetLogger(l *slog.Logger) *SlowLogBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.logger = l
+	return x
+}
+
+// 日志级别，缺省：slog.LevelWarn
+func (x *SlowLogBuilder) SetLevel(l slog.Level) *SlowLogBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.level = l
+	return x
+}
+
+// 按角色设置缺省阈值，mode：Write、Read、Backup
+func (x *SlowLogBuilder) SetThreshold(mode int, d time.Duration) error {
+	if err := CheckMode(mode); err != nil {
+		return err
+	}
+
+	if d < 0 {
+		return errors.New("threshold can't be less than 0")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.thresholds == nil {
+		x.thresholds = make(map[int]time.Duration)
+	}
+
+	x.thresholds[mode] = d
+	return nil
+}
+
+// 未经Drivers或Sharding构建的Driver的缺省阈值
+func (x *SlowLogBuilder) SetDefaultThreshold(d time.Duration) error {
+	if d < 0 {
+		return errors.New("threshold can't be less than 0")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.thresholds == nil {
+		x.thresholds = make(map[int]time.Duration)
+	}
+
+	x.thresholds[0] = d
+	return nil
+}
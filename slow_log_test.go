@@ -0,0 +1,95 @@
+package database
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowLog(t *testing.T) {
+	setFakeResult("UPDATE user SET phone = ? WHERE id = ?", &fakeResult{affected: 1})
+
+	var bucket bytes.Buffer
+	b := &SlowLogBuilder{}
+	b.SetLogger(slog.New(slog.NewTextHandler(&bucket, nil)))
+	_ = b.SetDefaultThreshold(time.Nanosecond)
+
+	slowLog, _ := b.Build()
+
+	d := newFakeDriver()
+	_ = d.AddInterceptor(slowLog)
+
+	if _, err := Exec(d, "UPDATE user SET phone = ? WHERE id = ?", "13000000001", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	got := bucket.String()
+	for _, want := range []string{"slow query", `args="[string(11), int]"`, "rows=1", "host=127.0.0.1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q; want contains %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "13000000001") {
+		t.Errorf("got %q; want args redacted", got)
+	}
+}
+
+func TestSlowLogRoleThreshold(t *testing.T) {
+	p, err := NewProfile(map[string]string{
+		ProfileId: "user", ProfileDriver: "fake", ProfileHost: "127.0.0.1", ProfileDatabase: "user", ProfileUsername: "root",
+		ProfileWrite: "true", ProfileRead: "true",
+		ProfileSlowThreshold: "200ms", ProfileReadSlowThreshold: "1s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &DriversBuilder{}
+	b.SetLazy(true)
+	if err := b.AddProfile(p); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slowLog, _ := (&SlowLogBuilder{}).Build()
+	writer, _ := d.GetWriter()
+	reader, _ := d.GetReader()
+
+	if got := slowLog.GetThreshold(writer); got != 200*time.Millisecond {
+		t.Errorf("got %v; want 200ms", got)
+	}
+
+	if got := slowLog.GetThreshold(reader); got != time.Second {
+		t.Errorf("got %v; want 1s", got)
+	}
+
+	p2, _ := NewProfile(map[string]string{
+		ProfileId: "user", ProfileDriver: "fake", ProfileHost: "127.0.0.1", ProfileDatabase: "user", ProfileUsername: "root",
+		ProfileWrite: "true", ProfileRead: "true",
+		ProfileSlowThreshold: "200ms", ProfileReadSlowThreshold: "2s",
+	})
+
+	b2 := &DriversBuilder{}
+	b2.SetLazy(true)
+	_ = b2.AddProfile(p2)
+
+	r, err := d.Reload(b2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.GetOpened() != 0 || r.GetKept() != 2 {
+		t.Errorf("got opened %d, kept %d; want 0, 2", r.GetOpened(), r.GetKept())
+	}
+
+	if got := slowLog.GetThreshold(reader); got != 2*time.Second {
+		t.Errorf("got %v; want 2s", got)
+	}
+}
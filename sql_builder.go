@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sql片段及其参数，片段内用?占位
+type sqlClause struct {
+	sql  string
+	args []interface{}
+}
+
+// 分库路由，执行时未指定Driver则按key取分库
+type shardRoute struct {
+	sharding *Sharding
+	key      interface{}
+}
+
+func (x *shardRoute) driver(driver *Driver) (*Driver, error) {
+	if driver != nil {
+		return driver, nil
+	}
+
+	if x.sharding == nil {
+		return nil, ErrNilDriver
+	}
+
+	return x.sharding.GetDriverByKey(x.key)
+}
+
+func isIdent(s string) bool {
+	if s == "" || isDigit(s[0]) {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isWord(s[i]) && !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 拼接WHERE、HAVING条件，多个条件用AND连接
+func writeConditions(b *strings.Builder, keyword string, args []interface{}, where []sqlClause) []interface{} {
+	for i, w := range where {
+		if i == 0 {
+			b.WriteString(" " + keyword + " ")
+		} else {
+			b.WriteString(" AND ")
+		}
+
+		if len(where) > 1 {
+			b.WriteString("(" + w.sql + ")")
+		} else {
+			b.WriteString(w.sql)
+		}
+
+		args = append(args, w.args...)
+	}
+
+	return args
+}
+
+// 执行写语句
+func execBuilt(ctx context.Context, driver *Driver, route *shardRoute, build func(name string) (string, []interface{}, error)) (sql.Result, error) {
+	d, err := route.driver(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := build(d.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	return ExecContext(ctx, d, query, args...)
+}
+
+func checkTable(table string) error {
+	if table == "" {
+		return errors.New("table can't be empty")
+	}
+
+	return nil
+}
+
+// 列名需为标识符，支持"表.列"，防止经quoteIdent原样拼入sql
+func checkColumns(columns []string) error {
+	for _, column := range columns {
+		for _, p := range strings.Split(column, ".") {
+			if !isIdent(p) {
+				return fmt.Errorf(`column "%s" must be an identifier`, column)
+			}
+		}
+	}
+
+	return nil
+}
+
+// UPDATE、DELETE需有条件，或显式调用All()
+func checkWhere(where []sqlClause, all bool) error {
+	if len(where) == 0 && !all {
+		return errors.New("where can't be empty, call All() to affect all rows")
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSelectQuery(t *testing.T) {
+	q := NewSelect("user u", "u.id", "u.name", "COUNT(o.id) AS orders").
+		LeftJoin("orders o", "o.user_id = u.id AND o.status = ?", 1).
+		Where("u.age > ?", 18).
+		Where("u.name <> '?'").
+		GroupBy("u.id", "u.name").
+		Having("COUNT(o.id) > ?", 2).
+		OrderByDesc("u.id").
+		Limit(10).
+		Offset(20)
+
+	got, args, err := q.ToSql("postgres")
+	want := `SELECT "u"."id", "u"."name", COUNT(o.id) AS orders FROM user u LEFT JOIN orders o ON o.user_id = u.id AND o.status = $1 WHERE (u.age > $2) AND (u.name <> '?') GROUP BY "u"."id", "u"."name" HAVING COUNT(o.id) > $3 ORDER BY "u"."id" DESC LIMIT 10 OFFSET 20`
+	if err != nil || got != want || fmt.Sprint(args) != "[1 18 2]" {
+		t.Errorf("got %q, %v, %v; want %q, [1 18 2], nil", got, args, err, want)
+	}
+
+	got2, _, _ := NewSelect("user").Where("id = ?", 1).ToSql("mysql")
+	want2 := "SELECT * FROM `user` WHERE id = ?"
+	if got2 != want2 {
+		t.Errorf("got %q; want %q", got2, want2)
+	}
+}
+
+func TestWriteQuery(t *testing.T) {
+	got, args, err := NewInsert("user", "name", "age").Values("foo", 1).Values("bar", 2).ToSql("postgres")
+	want := `INSERT INTO "user" ("name", "age") VALUES ($1, $2), ($3, $4)`
+	if err != nil || got != want || len(args) != 4 {
+		t.Errorf("got %q, %v, %v; want %q", got, args, err, want)
+	}
+
+	got2, args2, err := NewUpdate("user").Set("name", "foo").Where("id = ?", 1).ToSql("mysql")
+	want2 := "UPDATE `user` SET `name` = ? WHERE id = ?"
+	if err != nil || got2 != want2 || fmt.Sprint(args2) != "[foo 1]" {
+		t.Errorf("got %q, %v, %v; want %q", got2, args2, err, want2)
+	}
+
+	got3, _, err := NewDelete("user").Where("id = ?", 1).ToSql("sqlite3")
+	want3 := `DELETE FROM "user" WHERE id = ?`
+	if err != nil || got3 != want3 {
+		t.Errorf("got %q, %v; want %q", got3, err, want3)
+	}
+
+	if _, _, err := NewInsert("user", "name").Values("foo", 1).ToSql("mysql"); err == nil {
+		t.Errorf("got nil; want error")
+	}
+
+	want4 := "where can't be empty, call All() to affect all rows"
+	if _, _, err := NewUpdate("user").Set("name", "foo").ToSql("mysql"); err == nil || err.Error() != want4 {
+		t.Errorf("got %v; want %q", err, want4)
+	}
+
+	if _, _, err := NewDelete("user").ToSql("mysql"); err == nil || err.Error() != want4 {
+		t.Errorf("got %v; want %q", err, want4)
+	}
+
+	got5, _, err := NewDelete("user").All().ToSql("mysql")
+	if err != nil || got5 != "DELETE FROM `user`" {
+		t.Errorf("got %q, %v; want %q", got5, err, "DELETE FROM `user`")
+	}
+
+	want6 := `column "name) VALUES (1); --" must be an identifier`
+	if _, _, err := NewInsert("user", "name) VALUES (1); --").Values(1).ToSql("mysql"); err == nil || err.Error() != want6 {
+		t.Errorf("got %v; want %q", err, want6)
+	}
+
+	if _, _, err := NewUpdate("user").Set("name = 1, admin", 1).Where("id = ?", 1).ToSql("mysql"); err == nil {
+		t.Errorf("got nil; want error")
+	}
+}
+
+func TestSelectQueryShard(t *testing.T) {
+	setFakeResult("SELECT * FROM `order` WHERE user_id = ?", &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(7)}}})
+
+	s := &Sharding{id: "order", size: 2, drivers: []*Driver{newFakeDriver(), newFakeDriver()}}
+
+	var picked *Driver
+	for _, d := range s.drivers {
+		_ = d.AddInterceptor(InterceptorFunc(func(c *Call, next Handler) error {
+			picked = c.GetDriver()
+			return next(c)
+		}))
+	}
+
+	got, err := NewSelect("order").Where("user_id = ?", 3).Shard(s, 3).First(nil)
+	if err != nil || got["id"] != int64(7) {
+		t.Errorf("got %v, %v; want map[id:7], nil", got, err)
+	}
+
+	if picked != s.drivers[1] {
+		t.Errorf("got %v; want drivers[1] for key 3", picked)
+	}
+
+	if _, err := NewSelect("order").Where("user_id = ?", 4).Shard(s, 4).First(nil); err != nil || picked != s.drivers[0] {
+		t.Errorf("got %v, %v; want drivers[0] for key 4", picked, err)
+	}
+
+	d, err := s.GetDriverByKey(int64(-3))
+	if err != nil || d != s.drivers[1] {
+		t.Errorf("got %v, %v; want drivers[1], nil", d, err)
+	}
+}
+
+// 记录各分库Driver经拦截器执行的Driver
+func pickShard(s *Sharding) *(*Driver) {
+	picked := new(*Driver)
+	for _, d := range s.drivers {
+		_ = d.AddInterceptor(InterceptorFunc(func(c *Call, next Handler) error {
+			*picked = c.GetDriver()
+			return next(c)
+		}))
+	}
+
+	return picked
+}
+
+func TestWriteQueryExec(t *testing.T) {
+	setFakeResult(`INSERT INTO "user" ("name", "age") VALUES ($1, $2), ($3, $4)`, &fakeResult{affected: 2})
+	setFakeResult("UPDATE `order` SET `status` = ? WHERE user_id = ?", &fakeResult{affected: 3})
+	setFakeResult("DELETE FROM `order` WHERE user_id = ?", &fakeResult{affected: 1})
+	setFakeResult("INSERT INTO `order` (`user_id`) VALUES (?)", &fakeResult{affected: 1, insertId: 9})
+
+	// 按Driver的方言生成sql
+	r, err := NewInsert("user", "name", "age").Values("foo", 1).Values("bar", 2).Exec(newFakeNamedDriver("fakepg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _ := r.RowsAffected(); n != 2 {
+		t.Errorf("got %d; want 2", n)
+	}
+
+	s := &Sharding{id: "order", size: 2, drivers: []*Driver{newFakeDriver(), newFakeDriver()}}
+	picked := pickShard(s)
+
+	r2, err := NewUpdate("order").Set("status", 1).Where("user_id = ?", 3).Shard(s, 3).Exec(nil)
+	if err != nil || *picked != s.drivers[1] {
+		t.Fatalf("got %v, %v; want drivers[1], nil", *picked, err)
+	}
+
+	if n, _ := r2.RowsAffected(); n != 3 {
+		t.Errorf("got %d; want 3", n)
+	}
+
+	if _, err := NewDelete("order").Where("user_id = ?", 4).Shard(s, 4).ExecContext(context.Background(), nil); err != nil || *picked != s.drivers[0] {
+		t.Errorf("got %v, %v; want drivers[0], nil", *picked, err)
+	}
+
+	// 指定的Driver优先于分库路由
+	r3, err := NewInsert("order", "user_id").Values(3).Shard(s, 3).Exec(s.drivers[0])
+	if err != nil || *picked != s.drivers[0] {
+		t.Fatalf("got %v, %v; want drivers[0], nil", *picked, err)
+	}
+
+	if id, _ := r3.LastInsertId(); id != 9 {
+		t.Errorf("got %d; want 9", id)
+	}
+
+	if _, err := NewDelete("order").Where("user_id = ?", 4).Exec(nil); !errors.Is(err, ErrNilDriver) {
+		t.Errorf("got %v; want %v", err, ErrNilDriver)
+	}
+
+	// sql生成失败时不执行
+	*picked = nil
+	if _, err := NewUpdate("order").Set("status", 1).Shard(s, 3).Exec(nil); err == nil || *picked != nil {
+		t.Errorf("got %v, %v; want error without executing", *picked, err)
+	}
+}
+
+func TestSelectQueryFind(t *testing.T) {
+	query := "SELECT `u`.`id`, `o`.`amount` FROM user u JOIN orders o ON o.user_id = u.id AND o.status = ? WHERE (u.id > ?) AND (o.amount > ?) ORDER BY `o`.`amount` DESC, `u`.`id` LIMIT 2"
+	setFakeResult(query, &fakeResult{columns: []string{"id", "amount"}, rows: [][]driver.Value{{int64(1), int64(30)}, {int64(2), int64(20)}}})
+
+	s := &Sharding{id: "user", size: 2, drivers: []*Driver{newFakeDriver(), newFakeDriver()}}
+	picked := pickShard(s)
+
+	q := NewSelect("user u", "u.id", "o.amount").
+		Join("orders o", "o.user_id = u.id AND o.status = ?", 1).
+		Where("u.id > ?", 0).
+		Where("o.amount > ?", 10).
+		OrderByDesc("o.amount").
+		OrderBy("u.id").
+		Limit(2)
+
+	got, err := q.Shard(s, 3).Find(nil)
+	if err != nil || *picked != s.drivers[1] {
+		t.Fatalf("got %v, %v; want drivers[1], nil", *picked, err)
+	}
+
+	if fmt.Sprint(got) != "[map[amount:30 id:1] map[amount:20 id:2]]" {
+		t.Errorf("got %v; want 2 rows ordered by amount", got)
+	}
+
+	if _, err := q.Shard(s, 4).FindContext(context.Background(), nil); err != nil || *picked != s.drivers[0] {
+		t.Errorf("got %v, %v; want drivers[0], nil", *picked, err)
+	}
+
+	if _, err := NewSelect("user").Find(nil); !errors.Is(err, ErrNilDriver) {
+		t.Errorf("got %v; want %v", err, ErrNilDriver)
+	}
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// DELETE语句，条件片段内用?占位
+type DeleteQuery struct {
+	table string
+	where []sqlClause
+	all   bool // 无条件时删除全部行
+	route shardRoute
+}
+
+func NewDelete(table string) *DeleteQuery {
+	return &DeleteQuery{table: table}
+}
+
+// 多次调用用AND连接
+func (x *DeleteQuery) Where(cond string, args ...interface{}) *DeleteQuery {
+	x.where = append(x.where, sqlClause{sql: cond, args: args})
+	return x
+}
+
+// 无Where时删除全部行，未调用时无Where返回错误
+func (x *DeleteQuery) All() *DeleteQuery {
+	x.all = true
+	return x
+}
+
+// 执行时Driver为nil，按key路由到分库
+func (x *DeleteQuery) Shard(s *Sharding, key interface{}) *DeleteQuery {
+	x.route = shardRoute{sharding: s, key: key}
+	return x
+}
+
+// 按驱动名的方言生成sql和参数，如：mysql、postgres
+func (x *DeleteQuery) ToSql(name string) (string, []interface{}, error) {
+	if err := checkTable(x.table); err != nil {
+		return "", nil, err
+	}
+
+	if err := checkWhere(x.where, x.all); err != nil {
+		return "", nil, err
+	}
+
+	d := GetDialect(name)
+
+	var b strings.Builder
+	b.WriteString("DELETE FROM " + quoteIdent(d, x.table))
+
+	args := writeConditions(&b, "WHERE", nil, x.where)
+	return rebind(d, b.String()), args, nil
+}
+
+func (x *DeleteQuery) Exec(driver *Driver) (sql.Result, error) {
+	return x.ExecContext(context.Background(), driver)
+}
+
+func (x *DeleteQuery) ExecContext(ctx context.Context, driver *Driver) (sql.Result, error) {
+	return execBuilt(ctx, driver, &x.route, x.ToSql)
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// INSERT语句，支持多行
+type InsertQuery struct {
+	table   string
+	columns []string
+	rows    [][]interface{}
+	route   shardRoute
+}
+
+func NewInsert(table string, columns ...string) *InsertQuery {
+	return &InsertQuery{table: table, columns: columns}
+}
+
+// 一行的值，与columns一一对应，多次调用插入多行
+func (x *InsertQuery) Values(values ...interface{}) *InsertQuery {
+	x.rows = append(x.rows, values)
+	return x
+}
+
+// 执行时Driver为nil，按key路由到分库
+func (x *InsertQuery) Shard(s *Sharding, key interface{}) *InsertQuery {
+	x.route = shardRoute{sharding: s, key: key}
+	return x
+}
+
+// 按驱动名的方言生成sql和参数，如：mysql、postgres
+func (x *InsertQuery) ToSql(name string) (string, []interface{}, error) {
+	return x.toSql(GetDialect(name))
+}
+
+func (x *InsertQuery) toSql(d Dialect) (string, []interface{}, error) {
+	if err := checkTable(x.table); err != nil {
+		return "", nil, err
+	}
+
+	if len(x.columns) == 0 {
+		return "", nil, errors.New("columns can't be empty")
+	}
+
+	if err := checkColumns(x.columns); err != nil {
+		return "", nil, err
+	}
+
+	if len(x.rows) == 0 {
+		return "", nil, errors.New("values can't be empty")
+	}
+
+	row := "(" + strings.Repeat("?, ", len(x.columns)-1) + "?)"
+
+	var b strings.Builder
+	args := make([]interface{}, 0, len(x.columns)*len(x.rows))
+
+	b.WriteString("INSERT INTO " + quoteIdent(d, x.table) + " (" + quoteIdents(d, x.columns) + ") VALUES ")
+	for i, values := range x.rows {
+		if len(values) != len(x.columns) {
+			return "", nil, fmt.Errorf("row %d has %d values, want %d", i, len(values), len(x.columns))
+		}
+
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(row)
+		args = append(args, values...)
+	}
+
+	return rebind(d, b.String()), args, nil
+}
+
+func (x *InsertQuery) Exec(driver *Driver) (sql.Result, error) {
+	return x.ExecContext(context.Background(), driver)
+}
+
+func (x *InsertQuery) ExecContext(ctx context.Context, driver *Driver) (sql.Result, error) {
+	return execBuilt(ctx, driver, &x.route, x.ToSql)
+}
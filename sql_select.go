@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"strings"
+)
+
+// SELECT语句，条件等片段内用?占位，执行时按驱动改写
+type SelectQuery struct {
+	table   string
+	columns []string
+	joins   []sqlClause
+	where   []sqlClause
+	groupBy []string
+	having  []sqlClause
+	orderBy []string
+	limit   int
+	offset  int
+	route   shardRoute
+}
+
+// columns为空时查询*
+func NewSelect(table string, columns ...string) *SelectQuery {
+	return &SelectQuery{table: table, columns: columns, limit: -1}
+}
+
+// INNER JOIN，on如："u.id = o.user_id"
+func (x *SelectQuery) Join(table string, on string, args ...interface{}) *SelectQuery {
+	return x.join("JOIN", table, on, args)
+}
+
+func (x *SelectQuery) LeftJoin(table string, on string, args ...interface{}) *SelectQuery {
+	return x.join("LEFT JOIN", table, on, args)
+}
+
+func (x *SelectQuery) join(kind string, table string, on string, args []interface{}) *SelectQuery {
+	x.joins = append(x.joins, sqlClause{sql: kind + " " + table + " ON " + on, args: args})
+	return x
+}
+
+// 多次调用用AND连接，cond如："status = ? AND age > ?"
+func (x *SelectQuery) Where(cond string, args ...interface{}) *SelectQuery {
+	x.where = append(x.where, sqlClause{sql: cond, args: args})
+	return x
+}
+
+func (x *SelectQuery) GroupBy(columns ...string) *SelectQuery {
+	x.groupBy = append(x.groupBy, columns...)
+	return x
+}
+
+func (x *SelectQuery) Having(cond string, args ...interface{}) *SelectQuery {
+	x.having = append(x.having, sqlClause{sql: cond, args: args})
+	return x
+}
+
+func (x *SelectQuery) OrderBy(column string) *SelectQuery {
+	x.orderBy = append(x.orderBy, column)
+	return x
+}
+
+func (x *SelectQuery) OrderByDesc(column string) *SelectQuery {
+	x.orderBy = append(x.orderBy, column+" DESC")
+	return x
+}
+
+func (x *SelectQuery) Limit(n int) *SelectQuery {
+	x.limit = n
+	return x
+}
+
+func (x *SelectQuery) Offset(n int) *SelectQuery {
+	x.offset = n
+	return x
+}
+
+// 执行时Driver为nil，按key路由到分库
+func (x *SelectQuery) Shard(s *Sharding, key interface{}) *SelectQuery {
+	x.route = shardRoute{sharding: s, key: key}
+	return x
+}
+
+// 按驱动名的方言生成sql和参数，如：mysql、postgres
+func (x *SelectQuery) ToSql(name string) (string, []interface{}, error) {
+	if err := checkTable(x.table); err != nil {
+		return "", nil, err
+	}
+
+	d := GetDialect(name)
+
+	var b strings.Builder
+	var args []interface{}
+
+	b.WriteString("SELECT ")
+	if len(x.columns) == 0 {
+		b.WriteString("*")
+	} else {
+		b.WriteString(quoteIdents(d, x.columns))
+	}
+
+	b.WriteString(" FROM " + quoteIdent(d, x.table))
+
+	for _, j := range x.joins {
+		b.WriteString(" " + j.sql)
+		args = append(args, j.args...)
+	}
+
+	args = writeConditions(&b, "WHERE", args, x.where)
+
+	if len(x.groupBy) > 0 {
+		b.WriteString(" GROUP BY " + quoteIdents(d, x.groupBy))
+	}
+
+	args = writeConditions(&b, "HAVING", args, x.having)
+
+	if len(x.orderBy) > 0 {
+		orderBy := make([]string, len(x.orderBy))
+		for i, o := range x.orderBy {
+			column, desc := strings.CutSuffix(o, " DESC")
+			orderBy[i] = quoteIdent(d, column)
+			if desc {
+				orderBy[i] += " DESC"
+			}
+		}
+
+		b.WriteString(" ORDER BY " + strings.Join(orderBy, ", "))
+	}
+
+	b.WriteString(d.Limit(x.limit, x.offset))
+
+	return rebind(d, b.String()), args, nil
+}
+
+func (x *SelectQuery) Find(driver *Driver) ([]map[string]interface{}, error) {
+	return x.FindContext(context.Background(), driver)
+}
+
+func (x *SelectQuery) FindContext(ctx context.Context, driver *Driver) ([]map[string]interface{}, error) {
+	d, query, args, err := x.prepare(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return QueryContext(ctx, d, query, args...)
+}
+
+func (x *SelectQuery) First(driver *Driver) (map[string]interface{}, error) {
+	return x.FirstContext(context.Background(), driver)
+}
+
+func (x *SelectQuery) FirstContext(ctx context.Context, driver *Driver) (map[string]interface{}, error) {
+	d, query, args, err := x.prepare(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return QueryFirstContext(ctx, d, query, args...)
+}
+
+func (x *SelectQuery) prepare(driver *Driver) (*Driver, string, []interface{}, error) {
+	d, err := x.route.driver(driver)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	query, args, err := x.ToSql(d.GetName())
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return d, query, args, nil
+}
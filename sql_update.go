@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// UPDATE语句，条件片段内用?占位
+type UpdateQuery struct {
+	table   string
+	columns []string
+	values  []interface{}
+	where   []sqlClause
+	all     bool // 无条件时更新全部行
+	route   shardRoute
+}
+
+func NewUpdate(table string) *UpdateQuery {
+	return &UpdateQuery{table: table}
+}
+
+// 按调用顺序生成SET
+func (x *UpdateQuery) Set(column string, value interface{}) *UpdateQuery {
+	x.columns = append(x.columns, column)
+	x.values = append(x.values, value)
+	return x
+}
+
+// 多次调用用AND连接
+func (x *UpdateQuery) Where(cond string, args ...interface{}) *UpdateQuery {
+	x.where = append(x.where, sqlClause{sql: cond, args: args})
+	return x
+}
+
+// 无Where时更新全部行，未调用时无Where返回错误
+func (x *UpdateQuery) All() *UpdateQuery {
+	x.all = true
+	return x
+}
+
+// 执行时Driver为nil，按key路由到分库
+func (x *UpdateQuery) Shard(s *Sharding, key interface{}) *UpdateQuery {
+	x.route = shardRoute{sharding: s, key: key}
+	return x
+}
+
+// 按驱动名的方言生成sql和参数，如：mysql、postgres
+func (x *UpdateQuery) ToSql(name string) (string, []interface{}, error) {
+	if err := checkTable(x.table); err != nil {
+		return "", nil, err
+	}
+
+	d := GetDialect(name)
+
+	if len(x.columns) == 0 {
+		return "", nil, errors.New("set can't be empty")
+	}
+
+	if err := checkColumns(x.columns); err != nil {
+		return "", nil, err
+	}
+
+	if err := checkWhere(x.where, x.all); err != nil {
+		return "", nil, err
+	}
+
+	var b strings.Builder
+	args := append([]interface{}{}, x.values...)
+
+	b.WriteString("UPDATE " + quoteIdent(d, x.table) + " SET ")
+	for i, column := range x.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(quoteIdent(d, column) + " = ?")
+	}
+
+	args = writeConditions(&b, "WHERE", args, x.where)
+	return rebind(d, b.String()), args, nil
+}
+
+func (x *UpdateQuery) Exec(driver *Driver) (sql.Result, error) {
+	return x.ExecContext(context.Background(), driver)
+}
+
+func (x *UpdateQuery) ExecContext(ctx context.Context, driver *Driver) (sql.Result, error) {
+	return execBuilt(ctx, driver, &x.route, x.ToSql)
+}
@@ -0,0 +1,305 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 按sql指纹统计的拦截器，进程内统计次数、错误数、耗时分位数和行数
+// 零值可用，样本数和指纹数取缺省值StatementSamples、StatementLimit
+type Statements struct {
+	mu      sync.Mutex // ensures atomic writes; protects the following fields
+	samples int        // 每个sql指纹保留的耗时样本数，缺省：StatementSamples
+	limit   int        // 最多统计的sql指纹数，缺省：StatementLimit
+	data    map[string]*statement
+	dropped int64 // 超出limit未统计的调用数
+}
+
+type statement struct {
+	count     int64
+	errors    int64
+	rows      int64
+	total     time.Duration
+	durations []time.Duration // 最近samples次耗时，环形缓冲
+	next      int
+}
+
+func (x *Statements) Intercept(c *Call, next Handler) error {
+	err := next(c)
+	var d Dialect
+	if driver := c.GetDriver(); driver != nil {
+		d = driver.GetDialect()
+	}
+
+	fingerprint := fingerprintSql(d, c.GetQuery())
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.init()
+
+	s, ok := x.data[fingerprint]
+	if !ok {
+		if len(x.data) >= x.limit {
+			x.dropped++
+			return err
+		}
+
+		s = &statement{}
+		x.data[fingerprint] = s
+	}
+
+	s.count++
+	s.total += c.GetDuration()
+
+	if err != nil && !IsEmptyResult(err) {
+		s.errors++
+	}
+
+	if c.GetRows() > 0 {
+		s.rows += c.GetRows()
+	}
+
+	if len(s.durations) < x.samples {
+		s.durations = append(s.durations, c.GetDuration())
+	} else {
+		s.durations[s.next] = c.GetDuration()
+		s.next = (s.next + 1) % x.samples
+	}
+
+	return err
+}
+
+// 零值时取缺省的样本数和指纹数，需持有mu
+func (x *Statements) init() {
+	if x.samples <= 0 {
+		x.samples = StatementSamples
+	}
+
+	if x.limit <= 0 {
+		x.limit = StatementLimit
+	}
+
+	if x.data == nil {
+		x.data = make(map[string]*statement)
+	}
+}
+
+// 全部sql指纹的统计，按总耗时降序
+func (x *Statements) Snapshot() []*StatementStats {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	r := make([]*StatementStats, 0, len(x.data))
+	for fingerprint, s := range x.data {
+		r = append(r, s.stats(fingerprint))
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].total != r[j].total {
+			return r[i].total > r[j].total
+		}
+
+		return r[i].fingerprint < r[j].fingerprint
+	})
+
+	return r
+}
+
+// 按sql取统计，sql先按MySQL语法转为指纹
+func (x *Statements) Get(query string) (*StatementStats, bool) {
+	return x.get(nil, query)
+}
+
+// 按驱动名的方言将sql转为指纹，如：PostgreSQL的#>>是运算符
+func (x *Statements) GetByDriver(name string, query string) (*StatementStats, bool) {
+	return x.get(GetDialect(name), query)
+}
+
+func (x *Statements) get(d Dialect, query string) (*StatementStats, bool) {
+	fingerprint := fingerprintSql(d, query)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if s, ok := x.data[fingerprint]; ok {
+		return s.stats(fingerprint), true
+	}
+
+	return nil, false
+}
+
+// 超出sql指纹数上限，未统计的调用数
+func (x *Statements) GetDropped() int64 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.dropped
+}
+
+func (x *Statements) Reset() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.data = make(map[string]*statement)
+	x.dropped = 0
+}
+
+// 以JSON输出全部sql指纹的统计，耗时单位：毫秒
+func (x *Statements) WriteJSON(w io.Writer) error {
+	r := []map[string]interface{}{}
+	for _, s := range x.Snapshot() {
+		r = append(r, s.toMap())
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (x *statement) stats(fingerprint string) *StatementStats {
+	durations := append([]time.Duration{}, x.durations...)
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	return &StatementStats{
+		fingerprint: fingerprint,
+		count:       x.count,
+		errors:      x.errors,
+		rows:        x.rows,
+		total:       x.total,
+		p50:         percentile(durations, 0.50),
+		p95:         percentile(durations, 0.95),
+		p99:         percentile(durations, 0.99),
+	}
+}
+
+// 已升序的样本中，取第p分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+// sql指纹的统计
+type StatementStats struct {
+	fingerprint string
+	count       int64         // 调用次数
+	errors      int64         // 错误次数，不含查询结果空
+	rows        int64         // 返回行数或影响行数之和
+	total       time.Duration // 总耗时
+	p50         time.Duration
+	p95         time.Duration
+	p99         time.Duration
+}
+
+func (x *StatementStats) GetFingerprint() string {
+	return x.fingerprint
+}
+
+func (x *StatementStats) GetCount() int64 {
+	return x.count
+}
+
+func (x *StatementStats) GetErrors() int64 {
+	return x.errors
+}
+
+func (x *StatementStats) GetRows() int64 {
+	return x.rows
+}
+
+func (x *StatementStats) GetTotal() time.Duration {
+	return x.total
+}
+
+func (x *StatementStats) GetP50() time.Duration {
+	return x.p50
+}
+
+func (x *StatementStats) GetP95() time.Duration {
+	return x.p95
+}
+
+func (x *StatementStats) GetP99() time.Duration {
+	return x.p99
+}
+
+func (x *StatementStats) toMap() map[string]interface{} {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+
+	return map[string]interface{}{
+		"fingerprint": x.fingerprint,
+		"count":       x.count,
+		"errors":      x.errors,
+		"rows":        x.rows,
+		"total_ms":    ms(x.total),
+		"p50_ms":      ms(x.p50),
+		"p95_ms":      ms(x.p95),
+		"p99_ms":      ms(x.p99),
+	}
+}
+
+type StatementsBuilder struct {
+	mu      sync.Mutex // ensures atomic writes; protects the following fields
+	samples int
+	limit   int
+}
+
+func (x *StatementsBuilder) Build() (*Statements, error) {
+	if x.samples < 0 {
+		return nil, errors.New("samples can't be less than 0")
+	}
+
+	if x.limit < 0 {
+		return nil, errors.New("limit can't be less than 0")
+	}
+
+	samples := x.samples
+	if samples == 0 {
+		samples = StatementSamples
+	}
+
+	limit := x.limit
+	if limit == 0 {
+		limit = StatementLimit
+	}
+
+	return &Statements{
+		samples: samples,
+		limit:   limit,
+		data:    make(map[string]*statement),
+	}, nil
+}
+
+// 每个sql指纹保留的耗时样本数，缺省：StatementSamples
+func (x *StatementsBuilder) SetSamples(n int) *StatementsBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.samples = n
+	return x
+}
+
+// 最多统计的sql指纹数，缺省：StatementLimit
+func (x *StatementsBuilder) SetLimit(n int) *StatementsBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.limit = n
+	return x
+}
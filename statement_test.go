@@ -0,0 +1,92 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatements(t *testing.T) {
+	b := &StatementsBuilder{}
+	b.SetSamples(4)
+	x, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := func(c *Call) error { return nil }
+	for i := 1; i <= 6; i++ {
+		c := &Call{query: "SELECT * FROM user WHERE id = ?", duration: time.Duration(i) * time.Millisecond, rows: 1}
+		_ = x.Intercept(c, ok)
+	}
+
+	fail := errors.New("fake: failed")
+	c := &Call{query: "SELECT * FROM user WHERE id = 7", duration: 7 * time.Millisecond, rows: -1}
+	if got := x.Intercept(c, func(c *Call) error { return fail }); got != fail {
+		t.Errorf("got %v; want %v", got, fail)
+	}
+
+	s, found := x.Get("select * from user where id = 1")
+	if !found {
+		t.Fatalf("got not found; want found")
+	}
+
+	if s.GetCount() != 7 || s.GetErrors() != 1 || s.GetRows() != 6 || s.GetTotal() != 28*time.Millisecond {
+		t.Errorf("got count %d, errors %d, rows %d, total %v; want 7, 1, 6, 28ms", s.GetCount(), s.GetErrors(), s.GetRows(), s.GetTotal())
+	}
+
+	// 环形缓冲保留最近4次：4ms、5ms、6ms、7ms
+	if s.GetP50() != 5*time.Millisecond || s.GetP95() != 7*time.Millisecond || s.GetP99() != 7*time.Millisecond {
+		t.Errorf("got p50 %v, p95 %v, p99 %v; want 5ms, 7ms, 7ms", s.GetP50(), s.GetP95(), s.GetP99())
+	}
+
+	var buf bytes.Buffer
+	if err := x.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0]["fingerprint"] != "select * from user where id = ?" || got[0]["p50_ms"] != float64(5) {
+		t.Errorf("got %v; want one statement with p50_ms 5", got)
+	}
+}
+
+func TestStatementsZeroValue(t *testing.T) {
+	x := &Statements{}
+	_ = x.Intercept(&Call{query: "SELECT 1", duration: time.Millisecond}, func(c *Call) error { return nil })
+
+	if s, found := x.Get("SELECT 2"); !found || s.GetCount() != 1 {
+		t.Errorf("got %v, %v; want found with count 1", s, found)
+	}
+
+	var buf bytes.Buffer
+	x.Reset()
+	if err := x.WriteJSON(&buf); err != nil || buf.String() != "[]\n" {
+		t.Errorf("got %q, %v; want %q", buf.String(), err, "[]\n")
+	}
+}
+
+func TestStatementsDialect(t *testing.T) {
+	x := &Statements{}
+	pg := newFakeNamedDriver("fakepg")
+
+	ok := func(c *Call) error { return nil }
+	_ = x.Intercept(&Call{driver: pg, query: "SELECT data #>> '{a}' FROM t WHERE id = 1"}, ok)
+	_ = x.Intercept(&Call{driver: pg, query: "SELECT data #>> '{a}' FROM u WHERE id = 1"}, ok)
+
+	got := x.Snapshot()
+	if len(got) != 2 {
+		t.Fatalf("got %d fingerprints; want 2", len(got))
+	}
+
+	s, found := x.GetByDriver("fakepg", "SELECT data #>> '{b}' FROM t WHERE id = 2")
+	if !found || s.GetFingerprint() != "select data #>> ? from t where id = ?" {
+		t.Errorf("got %v, %v; want fingerprint with #>> operator", s, found)
+	}
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 连接池统计，按唯一标识、角色、第n个库和地址标注
+type DriverStats struct {
+	id       string      // 唯一标识
+	role     string      // 角色，writer、reader、backup
+	num      int         // 第n个库，缺省：-1-不分库
+	host     string      // 域名或Ip
+	port     int         // 端口
+	database string      // 数据库名
+	stats    sql.DBStats // 连接池统计，懒加载且未使用时为零值
+	grouped  bool        // 按角色汇总，不含第n个库和地址
+}
+
+// 连接池统计，懒加载且未使用时，不打开sql.DB，返回零值
+func (x *Driver) Stats() *DriverStats {
+	r := &DriverStats{
+		id:   x.id,
+		role: ModeName(x.mode),
+		num:  x.num,
+	}
+
+	if s := x.GetSchema(); s != nil {
+		r.host = s.GetHost()
+		r.port = s.GetPort()
+		r.database = s.GetDatabase()
+	}
+
+	x.mu.Lock()
+	db := x.db
+	x.mu.Unlock()
+
+	if db != nil {
+		r.stats = db.Stats()
+	}
+
+	return r
+}
+
+// 主库、从库和备库的连接池统计
+func (x *Drivers) Stats() []*DriverStats {
+	var r []*DriverStats
+	for _, d := range x.all() {
+		r = append(r, d.Stats())
+	}
+
+	return r
+}
+
+// 各分库的连接池统计
+func (x *Sharding) Stats() []*DriverStats {
+	var r []*DriverStats
+	for _, d := range x.GetDrivers() {
+		r = append(r, d.Stats())
+	}
+
+	return r
+}
+
+// 全部主从库和分库的连接池统计，按唯一标识升序
+func (x *Registry) Stats() []*DriverStats {
+	var r []*DriverStats
+	for _, id := range x.GetIds() {
+		x.mu.RLock()
+		d, s := x.drivers[id], x.shardings[id]
+		x.mu.RUnlock()
+
+		if d != nil {
+			r = append(r, d.Stats()...)
+		}
+
+		if s != nil {
+			r = append(r, s.Stats()...)
+		}
+	}
+
+	return r
+}
+
+// 按唯一标识和角色汇总连接池统计，保持首次出现的顺序，汇总后num为-1、地址为空
+// 如：WritePrometheus(w, GroupStats(registry.Stats()))
+func GroupStats(stats []*DriverStats) []*DriverStats {
+	var r []*DriverStats
+	groups := make(map[[2]string]*DriverStats)
+
+	for _, s := range stats {
+		key := [2]string{s.id, s.role}
+		g, ok := groups[key]
+		if !ok {
+			g = &DriverStats{id: s.id, role: s.role, num: -1, grouped: true}
+			groups[key] = g
+			r = append(r, g)
+		}
+
+		g.stats.MaxOpenConnections += s.stats.MaxOpenConnections
+		g.stats.OpenConnections += s.stats.OpenConnections
+		g.stats.InUse += s.stats.InUse
+		g.stats.Idle += s.stats.Idle
+		g.stats.WaitCount += s.stats.WaitCount
+		g.stats.WaitDuration += s.stats.WaitDuration
+		g.stats.MaxIdleClosed += s.stats.MaxIdleClosed
+		g.stats.MaxIdleTimeClosed += s.stats.MaxIdleTimeClosed
+		g.stats.MaxLifetimeClosed += s.stats.MaxLifetimeClosed
+	}
+
+	return r
+}
+
+func (x *DriverStats) GetId() string {
+	return x.id
+}
+
+func (x *DriverStats) GetRole() string {
+	return x.role
+}
+
+func (x *DriverStats) GetNum() int {
+	return x.num
+}
+
+func (x *DriverStats) GetHost() string {
+	return x.host
+}
+
+func (x *DriverStats) GetPort() int {
+	return x.port
+}
+
+func (x *DriverStats) GetDatabase() string {
+	return x.database
+}
+
+func (x *DriverStats) GetStats() sql.DBStats {
+	return x.stats
+}
+
+func (x *DriverStats) IsGrouped() bool {
+	return x.grouped
+}
+
+func (x *DriverStats) toMap() map[string]interface{} {
+	r := map[string]interface{}{
+		"id":                  x.id,
+		"role":                x.role,
+		"open_connections":    x.stats.OpenConnections,
+		"in_use":              x.stats.InUse,
+		"idle":                x.stats.Idle,
+		"wait_count":          x.stats.WaitCount,
+		"wait_duration":       x.stats.WaitDuration.Seconds(),
+		"max_lifetime_closed": x.stats.MaxLifetimeClosed,
+	}
+
+	if !x.grouped {
+		r["num"] = x.num
+		r["host"] = x.host
+		r["port"] = x.port
+		r["database"] = x.database
+	}
+
+	return r
+}
+
+// 以expvar发布连接池统计，如：PublishExpvar("database", registry.Stats)，name已发布时返回错误
+func PublishExpvar(name string, source func() []*DriverStats) error {
+	if name == "" {
+		return errors.New("name can't be empty")
+	}
+
+	if source == nil {
+		return errors.New("source can't be nil")
+	}
+
+	if expvar.Get(name) != nil {
+		return fmt.Errorf(`expvar "%s" has been published`, name)
+	}
+
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		r := []map[string]interface{}{}
+		for _, s := range source() {
+			r = append(r, s.toMap())
+		}
+
+		return r
+	}))
+
+	return nil
+}
+
+// 以Prometheus文本格式输出连接池统计
+func WritePrometheus(w io.Writer, stats []*DriverStats) error {
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(s sql.DBStats) float64
+	}{
+		{"database_open_connections", "gauge", "The number of established connections both in use and idle.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+		{"database_in_use_connections", "gauge", "The number of connections currently in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }},
+		{"database_idle_connections", "gauge", "The number of idle connections.", func(s sql.DBStats) float64 { return float64(s.Idle) }},
+		{"database_wait_count_total", "counter", "The total number of connections waited for.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }},
+		{"database_wait_duration_seconds_total", "counter", "The total time blocked waiting for a new connection.", func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }},
+		{"database_max_lifetime_closed_total", "counter", "The total number of connections closed due to max lifetime.", func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }},
+	}
+
+	var bucket bytes.Buffer
+	for _, m := range metrics {
+		fmt.Fprintf(&bucket, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&bucket, "# TYPE %s %s\n", m.name, m.kind)
+
+		for _, s := range stats {
+			fmt.Fprintf(&bucket, "%s{%s} %s\n", m.name, s.prometheusLabels(), strconv.FormatFloat(m.value(s.stats), 'g', -1, 64))
+		}
+	}
+
+	_, err := w.Write(bucket.Bytes())
+	return err
+}
+
+// 以Prometheus文本格式输出连接池统计的Handler，如：http.Handle("/metrics", PrometheusHandler(registry.Stats))
+func PrometheusHandler(source func() []*DriverStats) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := WritePrometheus(w, source()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// 标签值按Prometheus文本格式转义反斜杠、双引号和换行
+var prometheusEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// 按角色汇总时，仅含唯一标识和角色
+func (x *DriverStats) prometheusLabels() string {
+	pairs := [][2]string{
+		{"id", x.id},
+		{"role", x.role},
+	}
+
+	if !x.grouped {
+		pairs = append(pairs,
+			[2]string{"num", strconv.Itoa(x.num)},
+			[2]string{"host", x.host},
+			[2]string{"port", strconv.Itoa(x.port)},
+			[2]string{"database", x.database},
+		)
+	}
+
+	var r []string
+	for _, p := range pairs {
+		r = append(r, fmt.Sprintf(`%s="%s"`, p[0], prometheusEscaper.Replace(p[1])))
+	}
+
+	return strings.Join(r, ",")
+}
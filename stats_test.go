@@ -0,0 +1,112 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"strings"
+	"testing"
+)
+
+func newStatsDrivers(t *testing.T) *Drivers {
+	b := &DriversBuilder{}
+	_ = b.SetId("user")
+	_ = b.SetName("fake")
+	_ = b.AddWriter(&Schema{host: "127.0.0.1", port: Port, database: "user", username: "root"})
+	_ = b.AddReader(&Schema{host: "127.0.0.2", port: Port, database: "user", username: "root"})
+	_ = b.AddReader(&Schema{host: "127.0.0.3", port: Port, database: "user", username: "root"})
+	b.SetLazy(true)
+
+	d, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func TestDriversStats(t *testing.T) {
+	d := newStatsDrivers(t)
+	defer d.Close()
+
+	got := d.Stats()
+	if len(got) != 3 {
+		t.Fatalf("got %d stats; want 3", len(got))
+	}
+
+	if got[0].GetRole() != "writer" || got[1].GetRole() != "reader" || got[2].GetHost() != "127.0.0.3" {
+		t.Errorf("got %s %s %s; want writer reader 127.0.0.3", got[0].GetRole(), got[1].GetRole(), got[2].GetHost())
+	}
+
+	if got[0].GetStats().OpenConnections != 0 {
+		t.Errorf("got %d open connections; want 0 for lazy driver", got[0].GetStats().OpenConnections)
+	}
+
+	got[1].stats.InUse = 2
+	got[2].stats.InUse = 3
+
+	groups := GroupStats(got)
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups; want 2", len(groups))
+	}
+
+	if g := groups[1]; g.GetRole() != "reader" || g.GetStats().InUse != 5 || !g.IsGrouped() || g.GetNum() != -1 {
+		t.Errorf("got %s in use %d grouped %v num %d; want reader 5 true -1", g.GetRole(), g.GetStats().InUse, g.IsGrouped(), g.GetNum())
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	stats := []*DriverStats{
+		{id: "user", role: "writer", num: -1, host: "127.0.0.1", port: Port, database: "a\"b\\c\nd"},
+	}
+	stats[0].stats.InUse = 2
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, stats); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `database_in_use_connections{id="user",role="writer",num="-1",host="127.0.0.1",port="3306",database="a\"b\\c\nd"} 2`
+	if !strings.Contains(buf.String(), want+"\n") {
+		t.Errorf("got %q; want to contain %q", buf.String(), want)
+	}
+
+	if !strings.Contains(buf.String(), "# TYPE database_wait_count_total counter\n") {
+		t.Errorf("got %q; want TYPE line for database_wait_count_total", buf.String())
+	}
+
+	buf.Reset()
+	if err := WritePrometheus(&buf, GroupStats(stats)); err != nil {
+		t.Fatal(err)
+	}
+
+	want2 := `database_in_use_connections{id="user",role="writer"} 2`
+	if !strings.Contains(buf.String(), want2+"\n") {
+		t.Errorf("got %q; want to contain %q", buf.String(), want2)
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	d := newStatsDrivers(t)
+	defer d.Close()
+
+	name := "database_test_publish_expvar"
+	if err := PublishExpvar(name, d.Stats); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 || got[0]["role"] != "writer" || got[2]["host"] != "127.0.0.3" {
+		t.Errorf("got %v; want writer first and 127.0.0.3 last", got)
+	}
+
+	got2 := PublishExpvar(name, d.Stats)
+	want2 := `expvar "database_test_publish_expvar" has been published`
+	if got2 == nil || got2.Error() != want2 {
+		t.Errorf("got %v; want %q", got2, want2)
+	}
+}
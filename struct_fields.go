@@ -0,0 +1,91 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+)
+
+// 结构体字段，按db标签命名，无标签时按字段名小写
+type structField struct {
+	name   string      // 列名或参数名
+	tagged bool        // 是否有db标签
+	auto   bool        // db:"id,auto"，自增列，插入时忽略
+	value  interface{} // 字段值
+}
+
+// 按字段顺序，匿名嵌入的结构体展开在外层字段之后，db:"-"和未导出字段忽略，同名时外层字段优先
+// 嵌入的结构体指针为nil时按零值展开，保证同一类型的字段不变
+func structFields(v reflect.Value) []structField {
+	var r []structField
+	seen := make(map[string]bool)
+	walkStructFields(v, func(f structField) {
+		if !seen[f.name] {
+			seen[f.name] = true
+			r = append(r, f)
+		}
+	})
+
+	return r
+}
+
+func walkStructFields(v reflect.Value, visit func(f structField)) {
+	t := v.Type()
+	var embedded []reflect.Value
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if f.Anonymous && tag == "" {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv = reflect.Zero(fv.Type().Elem())
+				} else {
+					fv = fv.Elem()
+				}
+			}
+
+			if fv.Kind() == reflect.Struct {
+				embedded = append(embedded, fv)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		field := structField{name: name, tagged: name != "", auto: options == "auto", value: fv.Interface()}
+		if !field.tagged {
+			field.name = strings.ToLower(f.Name)
+		}
+
+		visit(field)
+	}
+
+	for _, e := range embedded {
+		walkStructFields(e, visit)
+	}
+}
+
+// 解引用指针和接口，nil时返回无效的reflect.Value
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
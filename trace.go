@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type Attribute struct {
+	key   string
+	value interface{}
+}
+
+func NewAttribute(key string, value interface{}) *Attribute {
+	return &Attribute{key: key, value: value}
+}
+
+func (x *Attribute) GetKey() string {
+	return x.key
+}
+
+func (x *Attribute) GetValue() interface{} {
+	return x.value
+}
+
+// 链路追踪，可适配OpenTelemetry等实现，测试时可用MemoryTracer
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs []*Attribute) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attrs ...*Attribute)
+	RecordError(err error)
+	End()
+}
+
+type tracing struct {
+	tracer Tracer
+}
+
+// 链路追踪拦截器，每次调用一个Span，如：AddInterceptor(NewTracing(tracer))
+func NewTracing(t Tracer) (Interceptor, error) {
+	if t == nil {
+		return nil, errors.New("tracer can't be nil")
+	}
+
+	return &tracing{tracer: t}, nil
+}
+
+func (x *tracing) Intercept(c *Call, next Handler) error {
+	driver := c.GetDriver()
+
+	attrs := []*Attribute{
+		NewAttribute(TraceDbSystem, DbSystem(driver.GetName())),
+		NewAttribute(TraceDbStatement, sanitize(driver.GetDialect(), c.GetQuery())),
+		NewAttribute(TraceDbOperation, c.GetMethod()),
+	}
+
+	name := c.GetMethod()
+	if s := driver.GetSchema(); s != nil {
+		name += " " + s.GetDatabase()
+		attrs = append(attrs,
+			NewAttribute(TraceDbName, s.GetDatabase()),
+			NewAttribute(TraceNetPeerName, s.GetHost()),
+			NewAttribute(TraceNetPeerPort, s.GetPort()),
+		)
+	}
+
+	if id := driver.GetId(); id != "" {
+		attrs = append(attrs, NewAttribute(TraceDbId, id))
+	}
+
+	if role := ModeName(driver.GetMode()); role != "" {
+		attrs = append(attrs, NewAttribute(TraceDbRole, role))
+	}
+
+	if num := driver.GetNum(); num >= 0 {
+		attrs = append(attrs, NewAttribute(TraceDbShard, num))
+	}
+
+	ctx, span := x.tracer.Start(c.GetContext(), name, attrs)
+	defer span.End()
+
+	c.SetContext(ctx)
+
+	err := next(c)
+	if c.GetRows() >= 0 {
+		span.SetAttributes(NewAttribute(TraceDbRows, c.GetRows()))
+	}
+
+	if err != nil && !IsEmptyResult(err) {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// 驱动名转数据库类型，如：mysql => mysql，postgres、pgx => postgresql
+func DbSystem(name string) string {
+	switch strings.ToLower(name) {
+	case "postgres", "pgx", "postgresql":
+		return "postgresql"
+	case "sqlite", "sqlite3":
+		return "sqlite"
+	case "sqlserver", "mssql":
+		return "mssql"
+	default:
+		return strings.ToLower(name)
+	}
+}
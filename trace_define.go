@@ -0,0 +1,14 @@
+package database
+
+const (
+	TraceDbSystem    = "db.system"     // 数据库类型，mysql、postgresql、...
+	TraceDbName      = "db.name"       // 数据库名
+	TraceDbStatement = "db.statement"  // 脱敏后的sql
+	TraceDbOperation = "db.operation"  // 调用方法，Exec、Find、First、Aggregate
+	TraceDbId        = "db.id"         // 唯一标识，经Drivers或Sharding构建时
+	TraceDbRole      = "db.role"       // 角色，writer、reader、backup
+	TraceDbShard     = "db.shard"      // 第n个库，分库时
+	TraceDbRows      = "db.rows"       // Exec：影响行数，查询：返回行数
+	TraceNetPeerName = "net.peer.name" // 域名或Ip
+	TraceNetPeerPort = "net.peer.port" // 端口
+)
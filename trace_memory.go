@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memorySpanKey struct{}
+
+// 内存Tracer，保存全部Span，用于测试
+type MemoryTracer struct {
+	mu    sync.Mutex // ensures atomic writes; protects the following list
+	spans []*MemorySpan
+}
+
+func (x *MemoryTracer) Start(ctx context.Context, name string, attrs []*Attribute) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent, _ := ctx.Value(memorySpanKey{}).(*MemorySpan)
+
+	span := &MemorySpan{
+		name:       name,
+		parent:     parent,
+		attributes: append([]*Attribute{}, attrs...),
+		start:      time.Now(),
+	}
+
+	x.mu.Lock()
+	x.spans = append(x.spans, span)
+	x.mu.Unlock()
+
+	return context.WithValue(ctx, memorySpanKey{}, span), span
+}
+
+func (x *MemoryTracer) GetSpans() []*MemorySpan {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return append([]*MemorySpan{}, x.spans...)
+}
+
+func (x *MemoryTracer) Reset() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.spans = nil
+}
+
+type MemorySpan struct {
+	mu         sync.Mutex // ensures atomic writes; protects the following fields
+	name       string
+	parent     *MemorySpan
+	attributes []*Attribute
+	errs       []error
+	start      time.Time
+	end        time.Time
+}
+
+func (x *MemorySpan) SetAttributes(attrs ...*Attribute) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.attributes = append(x.attributes, attrs...)
+}
+
+func (x *MemorySpan) RecordError(err error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.errs = append(x.errs, err)
+}
+
+func (x *MemorySpan) End() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.end.IsZero() {
+		x.end = time.Now()
+	}
+}
+
+func (x *MemorySpan) GetName() string {
+	return x.name
+}
+
+func (x *MemorySpan) GetParent() *MemorySpan {
+	return x.parent
+}
+
+func (x *MemorySpan) GetAttributes() []*Attribute {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return append([]*Attribute{}, x.attributes...)
+}
+
+// 按key取属性值，不存在时返回nil
+func (x *MemorySpan) GetAttribute(key string) interface{} {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for i := len(x.attributes) - 1; i >= 0; i-- {
+		if x.attributes[i].GetKey() == key {
+			return x.attributes[i].GetValue()
+		}
+	}
+
+	return nil
+}
+
+func (x *MemorySpan) GetErrors() []error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return append([]error{}, x.errs...)
+}
+
+func (x *MemorySpan) IsEnded() bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return !x.end.IsZero()
+}
+
+func (x *MemorySpan) GetDuration() time.Duration {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.end.Sub(x.start)
+}
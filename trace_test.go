@@ -0,0 +1,43 @@
+package database
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	setFakeResult("SELECT COUNT(1) AS aggregate FROM user WHERE name = 'root'", &fakeResult{columns: []string{"aggregate"}, rows: [][]driver.Value{{int64(3)}}})
+
+	tracer := &MemoryTracer{}
+	tracing, err := NewTracing(tracer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newFakeDriver()
+	_ = d.AddInterceptor(tracing)
+
+	got, err, _ := AggregateInt(d, "SELECT COUNT(1) AS aggregate FROM user WHERE name = 'root'")
+	if err != nil || got != 3 {
+		t.Fatalf("got %d, %v; want 3, nil", got, err)
+	}
+
+	spans := tracer.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans; want 1", len(spans))
+	}
+
+	span := spans[0]
+	if span.GetName() != "Aggregate test" || !span.IsEnded() {
+		t.Errorf("got %q, ended %v; want %q, true", span.GetName(), span.IsEnded(), "Aggregate test")
+	}
+
+	want := "SELECT COUNT(?) AS aggregate FROM user WHERE name = ?"
+	if got := span.GetAttribute(TraceDbStatement); got != want {
+		t.Errorf("got %v; want %q", got, want)
+	}
+
+	if got := span.GetAttribute(TraceNetPeerName); got != "127.0.0.1" {
+		t.Errorf("got %v; want %q", got, "127.0.0.1")
+	}
+}
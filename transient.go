@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// MySQL可重试的错误码
+var transientNumbers = map[int]bool{
+	1040: true, // too many connections
+	1205: true, // lock wait timeout exceeded
+	1213: true, // deadlock found
+	2002: true, // can't connect through socket
+	2003: true, // can't connect to server
+	2006: true, // server has gone away
+	2013: true, // lost connection during query
+}
+
+// PostgreSQL可重试的错误码，08开头的连接异常另行判断
+var transientStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"53300": true, // too_many_connections
+	"55P03": true, // lock_not_available
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// 是否是可重试的临时错误，如：driver.ErrBadConn、死锁、锁等待超时、连接被拒绝，不区分数据库
+// 按Driver区分时用driver.GetDialect().IsTransient(err)
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return MySQLDialect{}.IsTransient(err) || PostgresDialect{}.IsTransient(err) || SqliteDialect{}.IsTransient(err)
+}
+
+// 连接异常或网络超时
+func isConnError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "connection refused") || strings.Contains(msg, "connection reset") || strings.Contains(msg, "broken pipe")
+}
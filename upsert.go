@@ -0,0 +1,245 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// 方言可按行区分插入或更新时实现，返回用于RETURNING的布尔表达式，true为插入
+type upsertReporter interface {
+	UpsertInserted() string
+}
+
+// 插入或更新结果
+type UpsertResult struct {
+	affected int64 // 影响行数，MySQL的更新计为2
+	states   []int // 每行的结果，UpsertInserted、UpsertUpdated、UpsertUnchanged、UpsertUnknown
+}
+
+func (x *UpsertResult) GetAffected() int64 {
+	return x.affected
+}
+
+func (x *UpsertResult) GetStates() []int {
+	return x.states
+}
+
+// row为map[string]interface{}或结构体，冲突时更新updateColumns，缺省：除conflictColumns外的全部列
+// MySQL按任一唯一键冲突，PostgreSQL和SQLite按conflictColumns冲突
+func Upsert(driver *Driver, table string, row interface{}, conflictColumns []string, updateColumns []string) (*UpsertResult, error) {
+	var rows interface{}
+	if m, ok := row.(map[string]interface{}); ok {
+		rows = []map[string]interface{}{m}
+	} else {
+		v := reflect.ValueOf(row)
+		if !v.IsValid() {
+			return nil, errors.New("row can't be nil")
+		}
+
+		rows = reflect.Append(reflect.MakeSlice(reflect.SliceOf(v.Type()), 0, 1), v).Interface()
+	}
+
+	return (*Batch)(nil).Upsert(context.Background(), driver, table, rows, conflictColumns, updateColumns)
+}
+
+// rows为[]map[string]interface{}或结构体切片
+func UpsertBatch(driver *Driver, table string, rows interface{}, conflictColumns []string, updateColumns []string) (*UpsertResult, error) {
+	return (*Batch)(nil).Upsert(context.Background(), driver, table, rows, conflictColumns, updateColumns)
+}
+
+// PostgreSQL按RETURNING区分每行插入或更新，MySQL仅单行时按影响行数区分，GetStates与rows一一对应
+// 列名、conflictColumns和updateColumns需为标识符，map的键按列名拼入sql
+func (x *Batch) Upsert(ctx context.Context, driver *Driver, table string, rows interface{}, conflictColumns []string, updateColumns []string) (*UpsertResult, error) {
+	if driver == nil {
+		return nil, ErrNilDriver
+	}
+
+	columns, values, err := upsertRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	d := driver.GetDialect()
+	_, mysql := d.(MySQLDialect)
+	if !mysql && len(conflictColumns) == 0 {
+		return nil, errors.New("conflict columns can't be empty")
+	}
+
+	for _, c := range [][]string{columns, conflictColumns, updateColumns} {
+		if err := checkColumns(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if updateColumns == nil {
+		updateColumns = exceptColumns(columns, conflictColumns)
+	}
+
+	// ON CONFLICT ... DO NOTHING时，RETURNING仅返回插入的行
+	nothing := !mysql && len(updateColumns) == 0
+
+	chunks, err := x.chunk(d, table, columns, values)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := d.Upsert(conflictColumns, updateColumns)
+	reporter, report := d.(upsertReporter)
+	if report {
+		suffix += " RETURNING " + reporter.UpsertInserted()
+	}
+
+	for i := range chunks {
+		chunks[i].query += suffix
+	}
+
+	r := &UpsertResult{}
+	err = x.each(ctx, driver, chunks, func() { r = &UpsertResult{} }, func(ctx context.Context, e execer, query string, args []interface{}) (int64, error) {
+		size := len(args) / len(columns)
+
+		if report {
+			start := len(r.states)
+			n, err := r.scan(e.QueryContext(ctx, query, args...))
+			if err == nil {
+				r.pad(start, size, nothing)
+			}
+
+			return n, err
+		}
+
+		result, err := e.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+
+		n, _ := result.RowsAffected()
+		r.affected += n
+
+		state := UpsertUnknown
+		if mysql && size == 1 {
+			switch n {
+			case 0:
+				state = UpsertUnchanged
+			case 1:
+				state = UpsertInserted
+			default:
+				state = UpsertUpdated
+			}
+		}
+
+		for i := 0; i < size; i++ {
+			r.states = append(r.states, state)
+		}
+
+		return n, nil
+	})
+
+	if err != nil && x.GetTx() {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// 读取RETURNING的插入标记
+func (x *UpsertResult) scan(rows *sql.Rows, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for rows.Next() {
+		var inserted bool
+		if err := rows.Scan(&inserted); err != nil {
+			return n, joinClose(err, rows.Close())
+		}
+
+		if inserted {
+			x.states = append(x.states, UpsertInserted)
+		} else {
+			x.states = append(x.states, UpsertUpdated)
+		}
+
+		n++
+	}
+
+	x.affected += n
+	return n, joinClose(rows.Err(), rows.Close())
+}
+
+// RETURNING的行数少于本批行数时，无法逐行对应，本批的结果补齐为行数
+// DO NOTHING且无返回行时全部未变更，其余为UpsertUnknown
+func (x *UpsertResult) pad(start int, size int, nothing bool) {
+	n := len(x.states) - start
+	if n == size {
+		return
+	}
+
+	state := UpsertUnknown
+	if nothing && n == 0 {
+		state = UpsertUnchanged
+	}
+
+	x.states = x.states[:start]
+	for i := 0; i < size; i++ {
+		x.states = append(x.states, state)
+	}
+}
+
+// map按第一行的列名排序，结构体按字段顺序
+func upsertRows(rows interface{}) ([]string, [][]interface{}, error) {
+	maps, ok := rows.([]map[string]interface{})
+	if !ok {
+		return structRows(rows)
+	}
+
+	if len(maps) == 0 {
+		return nil, nil, errors.New("rows can't be empty")
+	}
+
+	columns := make([]string, 0, len(maps[0]))
+	for column := range maps[0] {
+		columns = append(columns, column)
+	}
+
+	sort.Strings(columns)
+
+	values := make([][]interface{}, len(maps))
+	for i, m := range maps {
+		if len(m) != len(columns) {
+			return nil, nil, fmt.Errorf("row %d has %d columns, want %d", i, len(m), len(columns))
+		}
+
+		values[i] = make([]interface{}, len(columns))
+		for j, column := range columns {
+			v, ok := m[column]
+			if !ok {
+				return nil, nil, fmt.Errorf(`row %d has no column "%s"`, i, column)
+			}
+
+			values[i][j] = v
+		}
+	}
+
+	return columns, values, nil
+}
+
+func exceptColumns(columns []string, except []string) []string {
+	skip := make(map[string]bool, len(except))
+	for _, column := range except {
+		skip[column] = true
+	}
+
+	var r []string
+	for _, column := range columns {
+		if !skip[column] {
+			r = append(r, column)
+		}
+	}
+
+	return r
+}
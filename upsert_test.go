@@ -0,0 +1,47 @@
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUpsert(t *testing.T) {
+	setFakeResult("INSERT INTO `user` (`age`, `id`, `name`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `age` = VALUES(`age`), `name` = VALUES(`name`)", &fakeResult{affected: 2})
+
+	got, err := Upsert(newFakeDriver(), "user", map[string]interface{}{"id": 1, "name": "foo", "age": 18}, []string{"id"}, nil)
+	if err != nil || got.GetAffected() != 2 || fmt.Sprint(got.GetStates()) != fmt.Sprint([]int{UpsertUpdated}) {
+		t.Errorf("got %v, %v; want affected 2, [updated]", got, err)
+	}
+
+	setFakeResult(`INSERT INTO "user" ("id", "name") VALUES ($1, $2), ($3, $4) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name" RETURNING (xmax = 0)`, &fakeResult{
+		columns: []string{"?column?"},
+		rows:    [][]driver.Value{{true}, {false}},
+	})
+
+	type user struct {
+		Id   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	got2, err := UpsertBatch(newFakeNamedDriver("fakepg"), "user", []user{{1, "foo"}, {2, "bar"}}, []string{"id"}, []string{"name"})
+	if err != nil || got2.GetAffected() != 2 || fmt.Sprint(got2.GetStates()) != fmt.Sprint([]int{UpsertInserted, UpsertUpdated}) {
+		t.Errorf("got %v, %v; want affected 2, [inserted updated]", got2, err)
+	}
+
+	setFakeResult(`INSERT INTO "user" ("id", "name") VALUES ($1, $2), ($3, $4), ($5, $6) ON CONFLICT ("id", "name") DO NOTHING RETURNING (xmax = 0)`, &fakeResult{
+		columns: []string{"?column?"},
+		rows:    [][]driver.Value{{true}},
+	})
+
+	got3, err := UpsertBatch(newFakeNamedDriver("fakepg"), "user", []user{{1, "foo"}, {2, "bar"}, {3, "baz"}}, []string{"id", "name"}, nil)
+	if err != nil || got3.GetAffected() != 1 || fmt.Sprint(got3.GetStates()) != fmt.Sprint([]int{UpsertUnknown, UpsertUnknown, UpsertUnknown}) {
+		t.Errorf("got %v, %v; want affected 1, 3 unknown states", got3, err)
+	}
+
+	_, err = Upsert(newFakeDriver(), "user", map[string]interface{}{"id": 1, "name = 1; DROP TABLE user; --": "x"}, []string{"id"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "must be an identifier") {
+		t.Errorf("got %v; want identifier error", err)
+	}
+}
@@ -0,0 +1,201 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 监听配置文件，变更时热加载Registry
+type Watcher struct {
+	mu       sync.Mutex // ensures serial reloads; protects the following fields
+	registry *Registry
+	path     string                                // 配置文件
+	interval time.Duration                         // 轮询间隔
+	loader   func(path string) ([]*Profile, error) // 读取配置文件
+	handler  func(r *ReloadResult, err error)      // 热加载结果
+	modTime  time.Time                             // 配置文件最后修改时间
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// 开始轮询配置文件，修改时间变更时热加载
+func (x *Watcher) Start() error {
+	info, err := os.Stat(x.path)
+	if err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.stop != nil {
+		return errors.New("watcher has been started")
+	}
+
+	x.modTime = info.ModTime()
+	x.stop = make(chan struct{})
+	x.done = make(chan struct{})
+
+	go x.watch(x.stop, x.done)
+	return nil
+}
+
+// 停止轮询，等待进行中的热加载结束
+func (x *Watcher) Stop() {
+	x.mu.Lock()
+	stop, done := x.stop, x.done
+	x.stop, x.done = nil, nil
+	x.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// 手动触发热加载
+func (x *Watcher) Trigger() (*ReloadResult, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.reload()
+}
+
+func (x *Watcher) watch(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(x.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(x.path)
+		if err != nil {
+			x.handle(nil, err)
+			continue
+		}
+
+		x.mu.Lock()
+		if !info.ModTime().Equal(x.modTime) {
+			x.modTime = info.ModTime()
+			_, _ = x.reload()
+		}
+		x.mu.Unlock()
+	}
+}
+
+func (x *Watcher) reload() (*ReloadResult, error) {
+	profiles, err := x.loader(x.path)
+	if err != nil {
+		x.handle(nil, err)
+		return nil, err
+	}
+
+	r, err := x.registry.Reload(profiles)
+	x.handle(r, err)
+	return r, err
+}
+
+func (x *Watcher) handle(r *ReloadResult, err error) {
+	if x.handler != nil {
+		x.handler(r, err)
+	}
+}
+
+func (x *Watcher) GetPath() string {
+	return x.path
+}
+
+func (x *Watcher) GetInterval() time.Duration {
+	return x.interval
+}
+
+type WatcherBuilder struct {
+	mu       sync.Mutex // ensures atomic writes; protects the following fields
+	registry *Registry
+	path     string
+	interval time.Duration
+	loader   func(path string) ([]*Profile, error)
+	handler  func(r *ReloadResult, err error)
+}
+
+func (x *WatcherBuilder) Build() (*Watcher, error) {
+	if x.registry == nil {
+		return nil, errors.New("registry can't be nil")
+	}
+
+	if x.path == "" {
+		return nil, errors.New("path can't be empty")
+	}
+
+	if x.loader == nil {
+		return nil, errors.New("loader can't be nil")
+	}
+
+	if x.interval < 0 {
+		return nil, errors.New("interval can't be less than 0")
+	}
+
+	interval := x.interval
+	if interval == 0 {
+		interval = WatchInterval
+	}
+
+	return &Watcher{
+		registry: x.registry,
+		path:     x.path,
+		interval: interval,
+		loader:   x.loader,
+		handler:  x.handler,
+	}, nil
+}
+
+func (x *WatcherBuilder) SetRegistry(r *Registry) *WatcherBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.registry = r
+	return x
+}
+
+func (x *WatcherBuilder) SetPath(s string) *WatcherBuilder {
+	s = strings.TrimSpace(s)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.path = s
+	return x
+}
+
+func (x *WatcherBuilder) SetInterval(d time.Duration) *WatcherBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.interval = d
+	return x
+}
+
+func (x *WatcherBuilder) SetLoader(f func(path string) ([]*Profile, error)) *WatcherBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.loader = f
+	return x
+}
+
+func (x *WatcherBuilder) SetHandler(f func(r *ReloadResult, err error)) *WatcherBuilder {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.handler = f
+	return x
+}
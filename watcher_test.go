@@ -0,0 +1,151 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 配置文件内容为user写库的host
+func newWatcher(t *testing.T, path string, results chan<- *ReloadResult) (*Watcher, *Registry) {
+	loader := func(path string) ([]*Profile, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := NewProfile(map[string]string{ProfileId: "user", ProfileDriver: "fake", ProfileHost: strings.TrimSpace(string(data)), ProfileDatabase: "user", ProfileUsername: "root", ProfileWrite: "true"})
+		if err != nil {
+			return nil, err
+		}
+
+		return []*Profile{p}, nil
+	}
+
+	profiles, err := loader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rb := &RegistryBuilder{}
+	rb.SetLazy(true)
+	for _, p := range profiles {
+		_ = rb.AddProfile(p)
+	}
+
+	r, err := rb.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &WatcherBuilder{}
+	w, err := b.SetRegistry(r).SetPath(path).SetInterval(10 * time.Millisecond).SetLoader(loader).SetHandler(func(r *ReloadResult, err error) {
+		if err == nil {
+			results <- r
+		}
+	}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return w, r
+}
+
+func writerHost(t *testing.T, r *Registry) string {
+	d, err := r.Get("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := d.GetWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return w.GetSchema().GetHost()
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database.ini")
+	if err := os.WriteFile(path, []byte("127.0.0.1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	results := make(chan *ReloadResult, 4)
+	w, r := newWatcher(t, path, results)
+	defer r.Close()
+
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Start(); err == nil {
+		t.Errorf("got nil; want started error")
+	}
+
+	if err := os.WriteFile(path, []byte("127.0.0.2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改时间的精度可能不足，显式推后
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-results:
+		if got.GetOpened() != 1 || got.GetClosed() != 1 {
+			t.Errorf("got opened %d, closed %d; want 1, 1", got.GetOpened(), got.GetClosed())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("got no reload; want reload after the file changed")
+	}
+
+	if got := writerHost(t, r); got != "127.0.0.2" {
+		t.Errorf("got %q; want %q", got, "127.0.0.2")
+	}
+
+	w.Stop()
+	w.Stop()
+
+	if err := os.WriteFile(path, []byte("127.0.0.3"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	latest := later.Add(time.Hour)
+	if err := os.Chtimes(path, latest, latest); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := writerHost(t, r); got != "127.0.0.2" {
+		t.Errorf("got %q after Stop; want %q", got, "127.0.0.2")
+	}
+
+	got, err := w.Trigger()
+	if err != nil || got.GetOpened() != 1 {
+		t.Errorf("got %v, %v; want opened 1", got, err)
+	}
+
+	if got := writerHost(t, r); got != "127.0.0.3" {
+		t.Errorf("got %q after Trigger; want %q", got, "127.0.0.3")
+	}
+}
+
+func TestWatcherBuilder(t *testing.T) {
+	w, err := (&WatcherBuilder{}).SetRegistry(&Registry{}).SetPath(" database.ini ").SetLoader(func(path string) ([]*Profile, error) { return nil, nil }).Build()
+	if err != nil || w.GetPath() != "database.ini" || w.GetInterval() != WatchInterval {
+		t.Errorf("got %v, %v; want database.ini and %v", w, err, WatchInterval)
+	}
+
+	if _, err := (&WatcherBuilder{}).SetRegistry(&Registry{}).SetPath("database.ini").Build(); err == nil {
+		t.Errorf("got nil; want loader error")
+	}
+
+	if err := w.Start(); err == nil {
+		t.Errorf("got nil; want stat error for a missing file")
+	}
+}